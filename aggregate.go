@@ -0,0 +1,117 @@
+package teorm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Count counts the rows matching the conditions of the Model or Table
+// query. dest is a *int64, or a pointer to a slice of structs or maps for
+// per partition or per window counts, see aggregate.
+func (db *DB) Count(dest interface{}) *DB {
+	return db.aggregate("COUNT(*)", "count", dest)
+}
+
+// Avg returns the average of column, see aggregate for dest
+func (db *DB) Avg(column string, dest interface{}) *DB {
+	return db.aggregate("AVG("+Quote(column)+")", "avg", dest)
+}
+
+// Sum returns the sum of column, see aggregate for dest
+func (db *DB) Sum(column string, dest interface{}) *DB {
+	return db.aggregate("SUM("+Quote(column)+")", "sum", dest)
+}
+
+// Min returns the minimum of column, see aggregate for dest
+func (db *DB) Min(column string, dest interface{}) *DB {
+	return db.aggregate("MIN("+Quote(column)+")", "min", dest)
+}
+
+// Max returns the maximum of column, see aggregate for dest
+func (db *DB) Max(column string, dest interface{}) *DB {
+	return db.aggregate("MAX("+Quote(column)+")", "max", dest)
+}
+
+// Spread returns the difference between the maximum and the minimum of
+// column, see aggregate for dest
+func (db *DB) Spread(column string, dest interface{}) *DB {
+	return db.aggregate("SPREAD("+Quote(column)+")", "spread", dest)
+}
+
+// Twa returns the time weighted average of column. TDengine requires a
+// single table or Partition("tbname") for it, see aggregate for dest
+func (db *DB) Twa(column string, dest interface{}) *DB {
+	return db.aggregate("TWA("+Quote(column)+")", "twa", dest)
+}
+
+// Percentile returns the p-th percentile (0 to 100) of column, TDengine
+// only supports it on a single table, see aggregate for dest
+func (db *DB) Percentile(column string, p float64, dest interface{}) *DB {
+	return db.aggregate("PERCENTILE("+Quote(column)+", "+strconv.FormatFloat(p, 'f', -1, 64)+")", "percentile", dest)
+}
+
+// Apercentile returns the approximate p-th percentile (0 to 100) of column,
+// see aggregate for dest
+func (db *DB) Apercentile(column string, p float64, dest interface{}) *DB {
+	return db.aggregate("APERCENTILE("+Quote(column)+", "+strconv.FormatFloat(p, 'f', -1, 64)+")", "apercentile", dest)
+}
+
+// Exists reports whether a row matches the conditions of the Model or Table query
+func (db *DB) Exists() (bool, error) {
+	tx := db.getInstance()
+	tx.Statement.Selects = []string{"_rowts"}
+	tx.Statement.Order, tx.Statement.LimitVal, tx.Statement.OffsetVal = "", 1, 0
+	tx.Statement.Partition, tx.Statement.Window = "", ""
+
+	rows, err := tx.Rows()
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return true, nil
+	}
+	return false, rows.Err()
+}
+
+// aggregate selects the aggregation expr AS alias. Without Partition and
+// Window dest is a pointer to a scalar, set to its zero value when no row
+// matches, and Order, Limit and Offset are ignored. With them dest is a
+// pointer to a slice of structs or maps, each row holding alias, the
+// partition columns and the window bounds as window_start and window_end:
+//
+//	var temps []struct {
+//		Location    string
+//		WindowStart time.Time
+//		Avg         float64
+//	}
+//	db.Model(&Sensor{}).Partition("location").Interval("1h").Avg("current_temp", &temps)
+func (db *DB) aggregate(expr, alias string, dest interface{}) *DB {
+	tx := db.getInstance()
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		tx.AddError(fmt.Errorf("%s requires a non nil pointer, got %T", alias, dest))
+		return tx
+	}
+
+	selects := []string{expr + " AS " + Quote(alias)}
+	if tx.Statement.Partition != "" || tx.Statement.Window != "" {
+		if kind := destValue.Elem().Kind(); kind != reflect.Slice {
+			tx.AddError(fmt.Errorf("%s with Partition or Window requires a pointer to a slice, got %T", alias, dest))
+			return tx
+		}
+		selects = append(selects, splitColumns([]string{tx.Statement.Partition})...)
+		if tx.Statement.Window != "" {
+			selects = append(selects, "_wstart AS `window_start`", "_wend AS `window_end`")
+		}
+	} else {
+		// A scalar aggregate has a single row, Order, Limit and Offset of the
+		// chain would only break or empty it
+		tx.Statement.Order, tx.Statement.LimitVal, tx.Statement.OffsetVal = "", 0, 0
+		// Aggregates over no rows return an empty result set
+		destValue.Elem().Set(reflect.Zero(destValue.Elem().Type()))
+	}
+	tx.Statement.Selects = selects
+	return tx.Scan(dest)
+}
@@ -0,0 +1,363 @@
+package teorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBatchWriterClosed is returned by Write and Flush after Close
+var ErrBatchWriterClosed = errors.New("batch writer is closed")
+
+// BatchWriterOptions configures a BatchWriter, zero values use the defaults
+type BatchWriterOptions[T any] struct {
+	// MaxRows flushes a sub table buffer when it holds that many rows, default 1000
+	MaxRows int
+	// MaxAge flushes a sub table buffer when its oldest row is that old, default 1s
+	MaxAge time.Duration
+	// MaxPending is the number of buffered and flushing rows before Write
+	// flushes the oldest buffer and blocks, default 10 * MaxRows
+	MaxPending int
+	// Workers is the number of concurrent flushes, default 1
+	Workers int
+	// OnError is called with the rows of every failed flush. When nil, the
+	// errors of size and age triggered flushes are returned by the next
+	// Flush or Close.
+	OnError func(*FlushError[T])
+}
+
+// FlushError reports a failed flush of a sub table buffer
+type FlushError[T any] struct {
+	Table string
+	Rows  []T
+	Err   error
+}
+
+func (e *FlushError[T]) Error() string {
+	return "flush of " + e.Table + " failed: " + e.Err.Error()
+}
+
+func (e *FlushError[T]) Unwrap() error {
+	return e.Err
+}
+
+// BatchWriter buffers rows per sub table and writes them with Create in
+// batches, flushing on size, on age or on Flush. It is safe for concurrent use.
+//
+//	w := teorm.NewBatchWriter[Sensor](db, teorm.BatchWriterOptions[Sensor]{MaxRows: 500})
+//	defer w.Close(ctx)
+//	w.Write(ctx, reading)
+type BatchWriter[T any] struct {
+	db   *DB
+	opts BatchWriterOptions[T]
+
+	mu       sync.Mutex
+	buffers  map[string]*writeBuffer[T]
+	closed   bool
+	inflight sync.WaitGroup // Write and Flush calls in progress
+	errs     []error        // Background flush errors not reported yet
+
+	slots    chan struct{} // One slot per pending row, provides backpressure
+	jobs     chan *flushJob[T]
+	stop     chan struct{}
+	workers  sync.WaitGroup
+	tickStop chan struct{}
+	tickDone chan struct{}
+}
+
+type writeBuffer[T any] struct {
+	rows  []T
+	since time.Time
+}
+
+type flushJob[T any] struct {
+	table string
+	rows  []T
+	done  chan error // nil for background flushes
+}
+
+// NewBatchWriter returns a BatchWriter writing rows of type T through db
+func NewBatchWriter[T any](db *DB, opts BatchWriterOptions[T]) *BatchWriter[T] {
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = 1000
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = time.Second
+	}
+	if opts.MaxPending < opts.MaxRows {
+		opts.MaxPending = 10 * opts.MaxRows
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+
+	w := &BatchWriter[T]{
+		db:      db,
+		opts:    opts,
+		buffers: make(map[string]*writeBuffer[T]),
+		slots:   make(chan struct{}, opts.MaxPending),
+		jobs:    make(chan *flushJob[T], opts.Workers),
+		stop:    make(chan struct{}),
+
+		tickStop: make(chan struct{}),
+		tickDone: make(chan struct{}),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		w.workers.Add(1)
+		go w.work()
+	}
+	go w.tick()
+	return w
+}
+
+// Write buffers rows. When MaxPending rows are pending it flushes the oldest
+// buffer and blocks until flushes complete or ctx is done.
+func (w *BatchWriter[T]) Write(ctx context.Context, rows ...T) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrBatchWriterClosed
+	}
+	w.inflight.Add(1)
+	w.mu.Unlock()
+	defer w.inflight.Done()
+
+	for _, row := range rows {
+		table, err := w.tableOf(&row)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case w.slots <- struct{}{}:
+		default:
+			// Pending rows may all sit in buffers below MaxRows, flush one
+			// rather than waiting for MaxAge
+			w.flushOldest()
+			select {
+			case w.slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		w.mu.Lock()
+		buf, ok := w.buffers[table]
+		if !ok {
+			buf = &writeBuffer[T]{since: time.Now()}
+			w.buffers[table] = buf
+		}
+		buf.rows = append(buf.rows, row)
+		var job *flushJob[T]
+		if len(buf.rows) >= w.opts.MaxRows {
+			job = &flushJob[T]{table: table, rows: buf.rows}
+			delete(w.buffers, table)
+		}
+		w.mu.Unlock()
+
+		if job != nil {
+			w.jobs <- job
+		}
+	}
+	return nil
+}
+
+// flushOldest sends the oldest buffer to the workers in background
+func (w *BatchWriter[T]) flushOldest() {
+	w.mu.Lock()
+	var table string
+	var oldest *writeBuffer[T]
+	for name, buf := range w.buffers {
+		if oldest == nil || buf.since.Before(oldest.since) {
+			table, oldest = name, buf
+		}
+	}
+	if oldest != nil {
+		delete(w.buffers, table)
+	}
+	w.mu.Unlock()
+
+	if oldest != nil {
+		w.jobs <- &flushJob[T]{table: table, rows: oldest.rows}
+	}
+}
+
+// Flush writes all buffered rows and waits for the writes to complete, the
+// returned error includes the background flush errors since the last call
+// when OnError is nil
+func (w *BatchWriter[T]) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		// The workers may be gone, nothing would receive the jobs
+		w.mu.Unlock()
+		return ErrBatchWriterClosed
+	}
+	w.inflight.Add(1)
+	w.mu.Unlock()
+	defer w.inflight.Done()
+
+	return w.flushBuffers(ctx)
+}
+
+// flushBuffers sends all buffers to the workers and waits for their writes
+func (w *BatchWriter[T]) flushBuffers(ctx context.Context) error {
+	w.mu.Lock()
+	var jobs []*flushJob[T]
+	for table, buf := range w.buffers {
+		jobs = append(jobs, &flushJob[T]{table: table, rows: buf.rows, done: make(chan error, 1)})
+		delete(w.buffers, table)
+	}
+	w.mu.Unlock()
+
+	for _, job := range jobs {
+		select {
+		case w.jobs <- job:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	for _, job := range jobs {
+		select {
+		case err := <-job.done:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	w.mu.Lock()
+	errs = append(w.errs, errs...)
+	w.errs = nil
+	w.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Close stops accepting rows, flushes the buffers and stops the workers. It
+// returns the flush errors not reported yet when OnError is nil. When ctx is
+// done first, the remaining flushes keep running in background.
+func (w *BatchWriter[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		w.inflight.Wait()
+		close(w.tickStop)
+		<-w.tickDone
+		err := w.flushBuffers(context.Background())
+		close(w.stop)
+		w.workers.Wait()
+
+		// Jobs drained after the flush may have failed too
+		w.mu.Lock()
+		err = errors.Join(append([]error{err}, w.errs...)...)
+		w.errs = nil
+		w.mu.Unlock()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tableOf returns the sub table a row is written to, rows are grouped by it
+func (w *BatchWriter[T]) tableOf(row *T) (string, error) {
+	if w.db.Statement.Table != "" {
+		return w.db.Statement.Table, nil
+	}
+	schema, err := Parse(row)
+	if err != nil {
+		return "", err
+	}
+	if schema.TableName != "" {
+		return schema.TableName, nil
+	}
+	return schema.Name, nil
+}
+
+func (w *BatchWriter[T]) work() {
+	defer w.workers.Done()
+	for {
+		select {
+		case job := <-w.jobs:
+			w.flush(job)
+		case <-w.stop:
+			// Drain jobs queued before stop
+			for {
+				select {
+				case job := <-w.jobs:
+					w.flush(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *BatchWriter[T]) flush(job *flushJob[T]) {
+	err := w.db.Create(job.rows).Error
+	for range job.rows {
+		<-w.slots
+	}
+
+	var flushErr error
+	if err != nil {
+		fe := &FlushError[T]{Table: job.table, Rows: job.rows, Err: err}
+		if w.opts.OnError != nil {
+			w.opts.OnError(fe)
+		} else if job.done == nil {
+			w.mu.Lock()
+			w.errs = append(w.errs, fe)
+			w.mu.Unlock()
+		}
+		flushErr = fe
+	}
+	if job.done != nil {
+		job.done <- flushErr
+	}
+}
+
+// tick flushes buffers older than MaxAge in background
+func (w *BatchWriter[T]) tick() {
+	defer close(w.tickDone)
+	ticker := time.NewTicker(w.opts.MaxAge / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.tickStop:
+			return
+		case now := <-ticker.C:
+			w.mu.Lock()
+			var jobs []*flushJob[T]
+			for table, buf := range w.buffers {
+				if now.Sub(buf.since) >= w.opts.MaxAge {
+					jobs = append(jobs, &flushJob[T]{table: table, rows: buf.rows})
+					delete(w.buffers, table)
+				}
+			}
+			w.mu.Unlock()
+
+			// Workers run until the ticker is stopped, sending can't block forever
+			for _, job := range jobs {
+				w.jobs <- job
+			}
+		}
+	}
+}
@@ -1,5 +1,21 @@
 package teorm
 
+import "context"
+
+// WithContext sets the context of the statements issued by the returned DB
+func (db *DB) WithContext(ctx context.Context) *DB {
+	tx := db.getInstance()
+	tx.Statement.Context = ctx
+	return tx
+}
+
+// Model specifies the model of Updates and of queries scanning into other destinations
+func (db *DB) Model(value interface{}) *DB {
+	tx := db.getInstance()
+	tx.Statement.Model = value
+	return tx
+}
+
 func (db *DB) Where(query string, args ...interface{}) *DB {
 	tx := db.getInstance()
 	tx.Statement.Conditions = append(tx.Statement.Conditions, query)
@@ -19,15 +35,27 @@ func (db *DB) Offset(offset int) *DB {
 	return tx
 }
 
+// Select specifies the queried columns, or the columns written by Create,
+// Save and Updates
 func (db *DB) Select(query interface{}, args ...interface{}) *DB {
 	tx := db.getInstance()
-	// Simplify: assume query is string for now
-	if str, ok := query.(string); ok {
-		tx.Statement.Selects = append(tx.Statement.Selects, str)
+	switch v := query.(type) {
+	case string:
+		tx.Statement.Selects = append(tx.Statement.Selects, v)
+	case []string:
+		tx.Statement.Selects = append(tx.Statement.Selects, v...)
 	}
 	return tx
 }
 
+// Omit specifies columns (or field names) that are not written by Create,
+// Save and Updates
+func (db *DB) Omit(columns ...string) *DB {
+	tx := db.getInstance()
+	tx.Statement.Omits = append(tx.Statement.Omits, columns...)
+	return tx
+}
+
 func (db *DB) Order(value interface{}) *DB {
 	tx := db.getInstance()
 	if str, ok := value.(string); ok {
@@ -43,3 +71,28 @@ func (db *DB) Group(value interface{}) *DB {
 	}
 	return tx
 }
+
+// Partition specifies the PARTITION BY columns, e.g. "tbname" or "location"
+func (db *DB) Partition(columns string) *DB {
+	tx := db.getInstance()
+	tx.Statement.Partition = columns
+	return tx
+}
+
+// Window specifies the window clause of aggregations, e.g.
+// "SESSION(ts, 10s)" or "STATE_WINDOW(status)"
+func (db *DB) Window(clause string) *DB {
+	tx := db.getInstance()
+	tx.Statement.Window = clause
+	return tx
+}
+
+// Interval specifies a time window of the given interval, sliding by
+// sliding when given, e.g. Interval("1h") or Interval("1h", "10m")
+func (db *DB) Interval(interval string, sliding ...string) *DB {
+	clause := "INTERVAL(" + interval + ")"
+	if len(sliding) > 0 && sliding[0] != "" {
+		clause += " SLIDING(" + sliding[0] + ")"
+	}
+	return db.Window(clause)
+}
@@ -1,161 +1,58 @@
 package teorm
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func (db *DB) ForceUpdate(value interface{}) *DB {
-	tx := db.getInstance()
+// columnFilter decides whether a column of a row is written
+type columnFilter func(elem reflect.Value, field *Field) bool
 
-	// Handle Slice
-	destValue := reflect.ValueOf(value)
-	if destValue.Kind() == reflect.Ptr {
-		destValue = destValue.Elem()
+// nonNilColumns skips nil pointer fields, the columns are left out of the
+// INSERT so existing values of a row with the same timestamp are kept
+func nonNilColumns(elem reflect.Value, field *Field) bool {
+	fVal := field.ReflectValueOf(elem)
+	if !fVal.IsValid() {
+		// Field of a nil embedded struct pointer
+		return false
 	}
-
-	if destValue.Kind() == reflect.Slice {
-		// Group elements by TableName
-		type BatchGroup struct {
-			Schema   *Schema
-			Elements []reflect.Value
-		}
-		groups := make(map[string]*BatchGroup)
-
-		for i := 0; i < destValue.Len(); i++ {
-			elem := destValue.Index(i)
-
-			// Parse Schema & TableName
-			var elemInterface interface{}
-			if elem.Kind() == reflect.Struct && elem.CanAddr() {
-				elemInterface = elem.Addr().Interface()
-			} else {
-				elemInterface = elem.Interface()
-			}
-
-			schema := Parse(elemInterface)
-			tableName := tx.Statement.Table
-
-			if tableName == "" {
-				if schema.TableName != "" {
-					tableName = schema.TableName
-				} else if len(schema.Tags) == 0 {
-					tableName = schema.Name
-				}
-			}
-
-			if tableName == "" {
-				tx.AddError(fmt.Errorf("table name is required at index %d", i))
-				return tx
-			}
-
-			// Add to group
-			if _, ok := groups[tableName]; !ok {
-				groups[tableName] = &BatchGroup{
-					Schema:   schema,
-					Elements: []reflect.Value{},
-				}
-			}
-			groups[tableName].Elements = append(groups[tableName].Elements, elem)
-		}
-
-		// Execute batch insert per group
-		for tableName, group := range groups {
-			groupTx := tx.getInstance()
-			groupTx.Statement.Table = tableName
-			groupTx.forceBatchInsert(group.Elements, group.Schema)
-			if groupTx.Error != nil {
-				tx.AddError(groupTx.Error)
-			}
-		}
-
-		return tx
-	}
-
-	schema := Parse(value)
-
-	// Determine Table Name
-	if tx.Statement.Table == "" {
-		if schema.TableName != "" {
-			tx.Statement.Table = schema.TableName
-		} else if len(schema.Tags) == 0 {
-			tx.Statement.Table = schema.Name
-		}
-	}
-
-	if tx.Statement.Table == "" {
-		tx.AddError(fmt.Errorf("table name is required, use db.Table('name') or implement Tabler interface"))
-		return tx
-	}
-
-	// Single insert re-using batch logic
-	tx.forceBatchInsert([]reflect.Value{destValue}, schema)
-	return tx
+	return fVal.Kind() != reflect.Ptr || !fVal.IsNil()
 }
 
-func (db *DB) forceBatchInsert(elements []reflect.Value, schema *Schema) {
-	if len(elements) == 0 {
-		return
-	}
-
-	var tagValues []interface{}
-	
-	firstElem := elements[0]
-	if firstElem.Kind() == reflect.Ptr {
-		firstElem = firstElem.Elem()
-	}
-
-	// 1. Prepare Metadata (Tags) from first element
-	for _, field := range schema.Tags {
-		fVal := firstElem.FieldByName(field.StructFieldName)
-		tagValues = append(tagValues, fVal.Interface())
-	}
-
-	// 2. Use ALL columns for ForceUpdate
-	var colNames []string
-	for _, field := range schema.Cols {
-		colNames = append(colNames, field.Name)
-	}
-
-	// Construct SQL
-	var sqlStr string
-
-	if len(tagValues) > 0 {
-		var tagValStrs []string
-		for _, tv := range tagValues {
-			tagValStrs = append(tagValStrs, formatTagValue(tv))
-		}
+// allColumns writes every column, nil pointers as NULL
+func allColumns(elem reflect.Value, field *Field) bool {
+	return true
+}
 
-		sqlStr = fmt.Sprintf("INSERT INTO %s (%s) USING %s TAGS (%s) VALUES %s",
-			db.Statement.Table,
-			strings.Join(colNames, ", "),
-			schema.Name,
-			strings.Join(tagValStrs, ", "),
-			buildInlinedValues(elements, schema, colNames),
-		)
-	} else {
-		sqlStr = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-			db.Statement.Table,
-			strings.Join(colNames, ", "),
-			buildInlinedValues(elements, schema, colNames),
-		)
-	}
+// Create inserts value into database, nil pointer fields are not written
+func (db *DB) Create(value interface{}) *DB {
+	return db.write(value, nonNilColumns)
+}
 
-	fmt.Fprintf(os.Stderr, "[DEBUG] Executing ForceUpdate SQL: %s\n", sqlStr)
+// Save inserts value writing all columns, nil pointer fields are written as
+// NULL and overwrite the values of an existing row with the same timestamp
+func (db *DB) Save(value interface{}) *DB {
+	return db.write(value, allColumns)
+}
 
-	_, err := db.DB.Exec(sqlStr)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[DEBUG] Error executing SQL: %v\n", err)
-		db.AddError(err)
-	}
+// ForceUpdate writes all columns of value.
+//
+// Deprecated: use Save.
+func (db *DB) ForceUpdate(value interface{}) *DB {
+	return db.Save(value)
 }
 
-// Create inserts value into database
-func (db *DB) Create(value interface{}) *DB {
+// write inserts value, a struct or a slice, grouping rows by target table.
+// filter selects the columns written for each row.
+func (db *DB) write(value interface{}, filter columnFilter) *DB {
 	tx := db.getInstance()
+	tx.Report = &WriteReport{}
 
 	// Handle Slice
 	destValue := reflect.ValueOf(value)
@@ -170,6 +67,7 @@ func (db *DB) Create(value interface{}) *DB {
 			Elements []reflect.Value
 		}
 		groups := make(map[string]*BatchGroup)
+		var tableNames []string // Tables in order of first appearance
 
 		for i := 0; i < destValue.Len(); i++ {
 			elem := destValue.Index(i)
@@ -182,7 +80,11 @@ func (db *DB) Create(value interface{}) *DB {
 				elemInterface = elem.Interface()
 			}
 
-			schema := Parse(elemInterface)
+			schema, err := Parse(elemInterface)
+			if err != nil {
+				tx.AddError(err)
+				return tx
+			}
 			tableName := tx.Statement.Table
 
 			if tableName == "" {
@@ -197,6 +99,10 @@ func (db *DB) Create(value interface{}) *DB {
 				tx.AddError(fmt.Errorf("table name is required at index %d", i))
 				return tx
 			}
+			if err := ValidateName(tableName, MaxTableNameLength); err != nil {
+				tx.AddError(fmt.Errorf("invalid table name at index %d: %w", i, err))
+				return tx
+			}
 
 			// Add to group
 			if _, ok := groups[tableName]; !ok {
@@ -204,25 +110,31 @@ func (db *DB) Create(value interface{}) *DB {
 					Schema:   schema,
 					Elements: []reflect.Value{},
 				}
+				tableNames = append(tableNames, tableName)
 			}
 			groups[tableName].Elements = append(groups[tableName].Elements, elem)
 		}
 
 		// Execute batch insert per group
-		for tableName, group := range groups {
-			groupTx := tx.getInstance()
-			groupTx.Statement.Table = tableName
-			groupTx.batchInsert(group.Elements, group.Schema)
-			if groupTx.Error != nil {
-				tx.AddError(groupTx.Error)
-				// Continue or break? Usually continue for other tables, but error is recorded
+		// A failed table doesn't stop the others, its error is recorded
+		for _, tableName := range tableNames {
+			group := groups[tableName]
+			groupFilter, err := tx.Statement.writeFilter(group.Schema, filter)
+			if err != nil {
+				tx.AddError(err)
+				return tx
 			}
+			tx.writeTable(tableName, group.Elements, group.Schema, groupFilter)
 		}
 
 		return tx
 	}
 
-	schema := Parse(value)
+	schema, err := Parse(value)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
 
 	// Determine Table Name
 	if tx.Statement.Table == "" {
@@ -237,80 +149,76 @@ func (db *DB) Create(value interface{}) *DB {
 		tx.AddError(fmt.Errorf("table name is required, use db.Table('name') or implement Tabler interface"))
 		return tx
 	}
+	if err := ValidateName(tx.Statement.Table, MaxTableNameLength); err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	filter, err = tx.Statement.writeFilter(schema, filter)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
 
-	tx.insert(destValue, schema)
+	// Single insert re-using batch logic
+	tx.writeTable(tx.Statement.Table, []reflect.Value{destValue}, schema, filter)
 	return tx
 }
 
-func (db *DB) insert(val reflect.Value, schema *Schema) {
-	// Re-use batchInsert for single element
-	db.batchInsert([]reflect.Value{val}, schema)
+// writeTable inserts the rows of one table and adds its TableReport to db.Report
+func (db *DB) writeTable(tableName string, elements []reflect.Value, schema *Schema, filter columnFilter) {
+	tableTx := db.getInstance()
+	tableTx.Error = nil
+	tableTx.Statement.Table = tableName
+	tableTx.tableReport = &TableReport{Table: tableName, RowsAttempted: len(elements)}
+
+	start := time.Now()
+	tableTx.batchInsert(elements, schema, filter)
+	report := tableTx.tableReport
+	report.Duration = time.Since(start)
+	report.Error = tableTx.Error
+
+	db.Report.Tables = append(db.Report.Tables, report)
+	db.RowsAffected += report.RowsWritten
+	if tableTx.Error != nil {
+		db.AddError(tableTx.Error)
+	}
 }
 
-func (db *DB) batchInsert(elements []reflect.Value, schema *Schema) {
+func (db *DB) batchInsert(elements []reflect.Value, schema *Schema, filter columnFilter) {
 	if len(elements) == 0 {
 		return
 	}
 
-	var tagValues []interface{}
-	var tagPlaceholders []string
-
-	// We assume all elements in this batch group have the same Schema structure (Tags, Cols)
-	// and same Tag Values (if they belong to the same subtable, tags must be same!)
-
-	firstElem := elements[0]
-	if firstElem.Kind() == reflect.Ptr {
-		firstElem = firstElem.Elem()
-	}
-
-	// 1. Prepare Metadata (Columns & Tags) from first element
-
-	// Extract Tags
-	for _, field := range schema.Tags {
-		fVal := firstElem.FieldByName(field.StructFieldName)
-		tagValues = append(tagValues, fVal.Interface())
-		tagPlaceholders = append(tagPlaceholders, "?")
+	// All elements of a batch target the same sub table, so they must share tag values
+	groups, err := db.tagGroups(elements, schema)
+	if err != nil {
+		db.AddError(err)
+		return
 	}
-
-	// Extract Column Names
-	// OLD: extracted from first element for all.
-	// NEW: now we use dynamic grouping below.
-
-	// 2. Collect Values for all rows
-	for _, elem := range elements {
-		if elem.Kind() == reflect.Ptr {
-			elem = elem.Elem()
+	if len(groups) > 1 {
+		for i, group := range groups {
+			if i > 0 {
+				if err := db.setGroupTags(schema, group); err != nil {
+					db.AddError(err)
+					return
+				}
+			}
+			db.batchInsert(group.Elements, schema, filter)
 		}
-
-		// For dynamic columns, we need to check if we can batch insert with same columns
-		// TDengine batch insert requires all rows to have same column structure?
-		// "INSERT INTO ... VALUES (row1), (row2)" - standard SQL requires consistent columns.
-		// If rows have different non-nil columns, we MUST split them into different INSERT statements.
-		// For now, let's assume we simply insert NULL for nil pointers in batch mode to keep performance high.
-		// Wait, user requirement is "prevent value set to 0".
-		// If we insert NULL, it overwrites old value with NULL.
-		// If user wants PARTIAL UPDATE (keep old value), TDengine doesn't support this via simple INSERT easily if row exists.
-		// But if the requirement is just "don't write 0", writing NULL is often what is expected for "no value".
-
-		// However, if user explicitly wants "don't touch this column", then we CANNOT include it in INSERT statement.
-		// This means we can't batch heterogeneous rows together in one INSERT statement.
-
-		// To strictly follow "partial insert" (only insert non-nil fields), we must:
-		// 1. Group by "Set of Non-Nil Columns".
-		// 2. Execute batch for each group.
-
-		// Let's implement this "Sub-Grouping by Column Signature".
+		return
 	}
 
-	// Refactored batchInsert implementation below to support Dynamic Column Grouping
-
-	// Map signature (bitmask or string of col names) -> list of elements
+	// "INSERT INTO ... VALUES (row1), (row2)" requires the same columns for
+	// all rows, so rows are grouped by the signature of their written columns
+	// and each column group is inserted with its own statement.
 	type ColumnGroup struct {
 		Signature string
 		ColNames  []string
 		Elements  []reflect.Value
 	}
-	colGroups := make(map[string]*ColumnGroup)
+	var colGroups []*ColumnGroup
+	colGroupIndex := make(map[string]*ColumnGroup)
 
 	for _, elem := range elements {
 		if elem.Kind() == reflect.Ptr {
@@ -321,15 +229,7 @@ func (db *DB) batchInsert(elements []reflect.Value, schema *Schema) {
 		var sigBuilder strings.Builder
 
 		for _, field := range schema.Cols {
-			fVal := elem.FieldByName(field.StructFieldName)
-
-			// Check if nil
-			isNil := false
-			if fVal.Kind() == reflect.Ptr && fVal.IsNil() {
-				isNil = true
-			}
-
-			if !isNil {
+			if filter(elem, field) {
 				activeColNames = append(activeColNames, field.Name)
 				sigBuilder.WriteString(field.Name)
 				sigBuilder.WriteString(",")
@@ -337,110 +237,150 @@ func (db *DB) batchInsert(elements []reflect.Value, schema *Schema) {
 		}
 
 		sig := sigBuilder.String()
-		if _, ok := colGroups[sig]; !ok {
-			colGroups[sig] = &ColumnGroup{
+		if _, ok := colGroupIndex[sig]; !ok {
+			colGroupIndex[sig] = &ColumnGroup{
 				Signature: sig,
 				ColNames:  activeColNames,
 				Elements:  []reflect.Value{},
 			}
+			colGroups = append(colGroups, colGroupIndex[sig])
 		}
-		colGroups[sig].Elements = append(colGroups[sig].Elements, elem)
+		colGroupIndex[sig].Elements = append(colGroupIndex[sig].Elements, elem)
 	}
 
 	// Execute INSERT for each Column Group
+	if db.tableReport != nil {
+		db.tableReport.ColumnGroups += len(colGroups)
+	}
 	for _, grp := range colGroups {
-		if len(grp.ColNames) == 0 {
-			// No columns to insert? Maybe only tags?
-			// If only tags, we can insert.
-		}
-		db.executeGroupBatchInsert(grp.Elements, schema, grp.ColNames, tagValues, tagPlaceholders)
+		db.executeGroupBatchInsert(grp.Elements, schema, grp.ColNames, groups[0].TagValues)
 	}
 }
 
-func (db *DB) executeGroupBatchInsert(elements []reflect.Value, schema *Schema, colNames []string, tagValues []interface{}, tagPlaceholders []string) {
-	var colPlaceholders []string
-	var allColValues []interface{}
+// tagGroup is a run of rows of one sub table sharing the same tag values
+type tagGroup struct {
+	TagValues []string // Inlined tag values in Schema.Tags order
+	Elements  []reflect.Value
+}
 
-	// Prepare placeholder for one row
-	singleRowPlaceholders := make([]string, len(colNames))
-	for i := range singleRowPlaceholders {
-		singleRowPlaceholders[i] = "?"
+// tagGroups groups the rows of one sub table by tag values, in order of
+// first appearance, and applies Config.TagPolicy when they diverge
+func (db *DB) tagGroups(elements []reflect.Value, schema *Schema) ([]*tagGroup, error) {
+	if len(schema.Tags) == 0 {
+		return []*tagGroup{{Elements: elements}}, nil
 	}
-	colPlaceholdersStr := "(" + strings.Join(singleRowPlaceholders, ", ") + ")"
 
+	var groups []*tagGroup
+	index := make(map[string]*tagGroup)
 	for _, elem := range elements {
-		if elem.Kind() == reflect.Ptr {
-			elem = elem.Elem()
+		tagValues, err := db.formatTagValues(reflect.Indirect(elem), schema)
+		if err != nil {
+			return nil, err
 		}
+		sig := strings.Join(tagValues, ",")
+		if _, ok := index[sig]; !ok {
+			index[sig] = &tagGroup{TagValues: tagValues}
+			groups = append(groups, index[sig])
+		}
+		index[sig].Elements = append(index[sig].Elements, elem)
+	}
+	if len(groups) == 1 {
+		return groups, nil
+	}
 
-		// Extract values for active columns only
-		for _, colName := range colNames {
-			// Find field by colName (Need reverse lookup or iterate schema)
-			// Optimization: Store mapping in Schema? For now iterate.
-			var fieldName string
-			for _, f := range schema.Cols {
-				if f.Name == colName {
-					fieldName = f.StructFieldName
-					break
-				}
-			}
-
-			fVal := elem.FieldByName(fieldName)
-
-			var val interface{}
-			// If ptr, dereference for value
-			if fVal.Kind() == reflect.Ptr {
-				val = fVal.Elem().Interface()
-			} else {
-				val = fVal.Interface()
-			}
+	policy := TagPolicyError
+	if db.Config != nil {
+		policy = db.Config.TagPolicy
+	}
+	conflict := fmt.Sprintf("rows of sub table %s have different tag values: (%s) and (%s)",
+		db.Statement.Table, strings.Join(groups[0].TagValues, ", "), strings.Join(groups[1].TagValues, ", "))
+
+	switch policy {
+	case TagPolicyWarn:
+		fmt.Fprintf(os.Stderr, "[WARN] %s, keeping the tags of the first row\n", conflict)
+		return []*tagGroup{{TagValues: groups[0].TagValues, Elements: elements}}, nil
+	case TagPolicySplit:
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("%s", conflict)
+	}
+}
 
-			allColValues = append(allColValues, val)
+// setGroupTags sets every tag of the sub table to those of group. The live
+// tags may differ from the previous group when the table already existed,
+// as USING ... TAGS only applies on creation, so no tag is skipped.
+func (db *DB) setGroupTags(schema *Schema, group *tagGroup) error {
+	for i, field := range schema.Tags {
+		sqlStr := fmt.Sprintf("ALTER TABLE %s SET TAG %s = %s", Quote(db.Statement.Table), Quote(field.Name), group.TagValues[i])
+		db.countStatement()
+		err := db.withRetry(func() error {
+			_, err := db.DB.ExecContext(db.context(), sqlStr)
+			return err
+		})
+		if err != nil {
+			return err
 		}
-		colPlaceholders = append(colPlaceholders, colPlaceholdersStr)
+	}
+	return nil
+}
+
+func (db *DB) executeGroupBatchInsert(elements []reflect.Value, schema *Schema, colNames []string, tagValues []string) {
+	// Optimization: Inline values to avoid parameter binding issues with TDengine
+	values, err := buildInlinedValues(elements, schema, colNames, db.precision())
+	if err != nil {
+		db.AddError(err)
+		return
 	}
 
 	// Construct SQL
 	var sqlStr string
-	//var args []interface{}
 
 	if len(tagValues) > 0 {
-		// Optimization: Inline TAG values to avoid parameter binding issues with TDengine
-		var tagValStrs []string
-		for _, tv := range tagValues {
-			tagValStrs = append(tagValStrs, formatTagValue(tv))
-		}
-
 		sqlStr = fmt.Sprintf("INSERT INTO %s (%s) USING %s TAGS (%s) VALUES %s",
-			db.Statement.Table,
-			strings.Join(colNames, ", "),
-			schema.Name,
-			strings.Join(tagValStrs, ", "),
-			// strings.Join(colPlaceholders, ", "), // Don't use placeholders
-			buildInlinedValues(elements, schema, colNames),
+			Quote(db.Statement.Table),
+			strings.Join(quoteAll(colNames), ", "),
+			Quote(schema.Name),
+			strings.Join(tagValues, ", "),
+			values,
 		)
-		// args = append(args, allColValues...) // Don't use args
 	} else {
 		sqlStr = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-			db.Statement.Table,
-			strings.Join(colNames, ", "),
-			// strings.Join(colPlaceholders, ", "),
-			buildInlinedValues(elements, schema, colNames),
+			Quote(db.Statement.Table),
+			strings.Join(quoteAll(colNames), ", "),
+			values,
 		)
-		// args = append(args, allColValues...)
 	}
 
-	// FORCE PRINT SQL to Stderr for debugging
-	fmt.Fprintf(os.Stderr, "[DEBUG] Executing SQL: %s\n", sqlStr)
-
-	_, err := db.DB.Exec(sqlStr)
+	db.countStatement()
+	var rowsAffected int64
+	err = db.withRetry(func() error {
+		res, err := db.DB.ExecContext(db.context(), sqlStr)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, err = res.RowsAffected(); err != nil {
+			rowsAffected = int64(len(elements))
+		}
+		return nil
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[DEBUG] Error executing SQL: %v\n", err)
 		db.AddError(err)
+		db.deadLetter(elements, schema, colNames, err)
+		return
+	}
+	if db.tableReport != nil {
+		db.tableReport.RowsWritten += rowsAffected
+	}
+}
+
+// countStatement counts a statement issued for the table being written
+func (db *DB) countStatement() {
+	if db.tableReport != nil {
+		db.tableReport.Statements++
 	}
 }
 
-func buildInlinedValues(elements []reflect.Value, schema *Schema, colNames []string) string {
+func buildInlinedValues(elements []reflect.Value, schema *Schema, colNames []string, precision string) (string, error) {
 	var rowStrs []string
 	for _, elem := range elements {
 		if elem.Kind() == reflect.Ptr {
@@ -448,40 +388,28 @@ func buildInlinedValues(elements []reflect.Value, schema *Schema, colNames []str
 		}
 		var valStrs []string
 		for _, colName := range colNames {
-			// Find field
-			var fieldName string
-			for _, f := range schema.Cols {
-				if f.Name == colName {
-					fieldName = f.StructFieldName
-					break
-				}
-			}
-			fVal := elem.FieldByName(fieldName)
-			var val interface{}
-			if fVal.Kind() == reflect.Ptr {
-				if fVal.IsNil() {
-					val = nil
-				} else {
-					val = fVal.Elem().Interface()
-				}
-			} else {
-				val = fVal.Interface()
+			val, err := schema.LookUpField(colName).DBValueOf(elem)
+			if err != nil {
+				return "", err
 			}
-			valStrs = append(valStrs, formatTagValue(val))
+			valStrs = append(valStrs, formatTagValue(val, precision))
 		}
 		rowStrs = append(rowStrs, "("+strings.Join(valStrs, ", ")+")")
 	}
-	return strings.Join(rowStrs, ", ")
+	return strings.Join(rowStrs, ", "), nil
 }
 
-func formatTagValue(v interface{}) string {
+// formatTagValue formats v as an inlined SQL literal, time.Time values are
+// written as epoch integers in the database precision
+func formatTagValue(v interface{}, precision string) string {
 	switch val := v.(type) {
 	case string:
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(val, "'", "\\'"))
+		return quoteString(val)
 	case []byte:
-		return fmt.Sprintf("'%s'", string(val))
+		// VARBINARY literal
+		return fmt.Sprintf("'\\x%s'", hex.EncodeToString(val))
 	case time.Time:
-		return fmt.Sprintf("'%s'", val.Format(time.RFC3339Nano))
+		return strconv.FormatInt(TimeToEpoch(val, precision), 10)
 	case nil:
 		return "NULL"
 	default:
@@ -491,40 +419,62 @@ func formatTagValue(v interface{}) string {
 			if rv.IsNil() {
 				return "NULL"
 			}
-			return formatTagValue(rv.Elem().Interface())
+			return formatTagValue(rv.Elem().Interface(), precision)
+		}
+		// JSON tag
+		if rv.Kind() == reflect.Map {
+			if rv.IsNil() {
+				return "NULL"
+			}
+			b, err := json.Marshal(val)
+			if err != nil {
+				return "NULL"
+			}
+			return formatTagValue(string(b), precision)
 		}
 		return fmt.Sprintf("%v", val)
 	}
 }
 
-func Explain(sqlStr string, args ...interface{}) string {
-	for _, arg := range args {
-		var val string
-		switch v := arg.(type) {
-		case string:
-			val = fmt.Sprintf("'%s'", strings.ReplaceAll(v, "'", "\\'"))
-		case []byte:
-			val = fmt.Sprintf("'%s'", string(v))
-		case time.Time:
-			val = fmt.Sprintf("'%s'", v.UTC().Format(time.RFC3339Nano))
-		case nil:
-			val = "NULL"
-		default:
-			// Handle pointer dereference for printing if needed, though args usually have values
-			rv := reflect.ValueOf(v)
-			if rv.Kind() == reflect.Ptr {
-				if rv.IsNil() {
-					val = "NULL"
-				} else {
-					val = fmt.Sprintf("%v", rv.Elem().Interface())
-				}
-			} else {
-				val = fmt.Sprintf("%v", v)
+// explain inlines args into the placeholders of sqlStr using the database
+// precision for timestamps, placeholders inside quoted literals are kept
+func (db *DB) explain(sqlStr string, args ...interface{}) string {
+	if len(args) == 0 {
+		return sqlStr
+	}
+	var sb strings.Builder
+	var quote rune
+	escaped := false
+	argIdx := 0
+	for _, r := range sqlStr {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
 			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?' && argIdx < len(args):
+			sb.WriteString(formatTagValue(args[argIdx], db.precision()))
+			argIdx++
+			continue
 		}
-		sqlStr = strings.Replace(sqlStr, "?", val, 1)
+		sb.WriteRune(r)
 	}
-	return sqlStr
+	return sb.String()
+}
+
+// Explain inlines args into the placeholders of sqlStr, timestamps are
+// written as epoch milliseconds.
+//
+// Deprecated: statements are inlined in the precision of the database,
+// which Explain doesn't know.
+func Explain(sqlStr string, args ...interface{}) string {
+	return (&DB{Statement: &Statement{}}).explain(sqlStr, args...)
 }
 
 // Table specifies the table name
@@ -0,0 +1,115 @@
+package teorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DatabaseOptions are the options of CREATE DATABASE and ALTER DATABASE,
+// zero values are left to the TDengine defaults
+type DatabaseOptions struct {
+	Keep       string // e.g. "3650d" or "30d,365d,3650d"
+	Duration   string // e.g. "10d"
+	Precision  string // "ms", "us" or "ns", create only
+	VGroups    int    // create only
+	Replica    int
+	CacheModel string // "none", "last_row", "last_value" or "both"
+	CacheSize  int    // MB per vnode
+	WalLevel   int    // 1 or 2
+}
+
+// clauses returns the option clauses, alter restricts them to alterable options
+func (o DatabaseOptions) clauses(alter bool) ([]string, error) {
+	var clauses []string
+	if o.Keep != "" {
+		clauses = append(clauses, "KEEP "+o.Keep)
+	}
+	if o.Duration != "" {
+		if alter {
+			return nil, fmt.Errorf("DURATION can not be altered")
+		}
+		clauses = append(clauses, "DURATION "+o.Duration)
+	}
+	if o.Precision != "" {
+		if alter {
+			return nil, fmt.Errorf("PRECISION can not be altered")
+		}
+		if !validPrecision(o.Precision) {
+			return nil, fmt.Errorf("invalid precision %q, must be one of ms, us, ns", o.Precision)
+		}
+		clauses = append(clauses, fmt.Sprintf("PRECISION '%s'", o.Precision))
+	}
+	if o.VGroups > 0 {
+		if alter {
+			return nil, fmt.Errorf("VGROUPS can not be altered")
+		}
+		clauses = append(clauses, fmt.Sprintf("VGROUPS %d", o.VGroups))
+	}
+	if o.Replica > 0 {
+		clauses = append(clauses, fmt.Sprintf("REPLICA %d", o.Replica))
+	}
+	if o.CacheModel != "" {
+		clauses = append(clauses, fmt.Sprintf("CACHEMODEL '%s'", o.CacheModel))
+	}
+	if o.CacheSize > 0 {
+		clauses = append(clauses, fmt.Sprintf("CACHESIZE %d", o.CacheSize))
+	}
+	if o.WalLevel > 0 {
+		clauses = append(clauses, fmt.Sprintf("WAL_LEVEL %d", o.WalLevel))
+	}
+	return clauses, nil
+}
+
+func (m migrator) CreateDatabase(name string, options DatabaseOptions) error {
+	clauses, err := options.clauses(false)
+	if err != nil {
+		return err
+	}
+	return m.exec(strings.TrimSpace(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s %s", Quote(name), strings.Join(clauses, " "))))
+}
+
+func (m migrator) AlterDatabase(name string, options DatabaseOptions) error {
+	clauses, err := options.clauses(true)
+	if err != nil {
+		return err
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+	return m.exec(fmt.Sprintf("ALTER DATABASE %s %s", Quote(name), strings.Join(clauses, " ")))
+}
+
+func (m migrator) DropDatabase(name string) error {
+	return m.exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", Quote(name)))
+}
+
+func (m migrator) HasDatabase(name string) bool {
+	names, err := m.queryStrings(fmt.Sprintf("SELECT name FROM information_schema.ins_databases WHERE name = %s", formatTagValue(strings.ToLower(name), m.db.precision())))
+	return err == nil && len(names) > 0
+}
+
+// Use opens a DB bound to database name, with the precision of that
+// database. It has its own connection pool, release it with Close when done.
+func (db *DB) Use(name string) (*DB, error) {
+	config := &Config{}
+	if db.Config != nil {
+		*config = *db.Config
+	}
+	config.Precision = ""
+
+	return open(dsnWithDatabase(db.dsn, name), config)
+}
+
+// dsnWithDatabase replaces the database of a [user[:password]@][net[(addr)]]/dbname[?params] DSN
+func dsnWithDatabase(dsn, name string) string {
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return dsn + "/" + name
+	}
+	rest := dsn[slash+1:]
+	params := ""
+	if q := strings.Index(rest, "?"); q >= 0 {
+		params = rest[q:]
+	}
+	return dsn[:slash+1] + name + params
+}
@@ -0,0 +1,124 @@
+package teorm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// DeadLetter receives the rows of INSERT statements that failed after all
+// retries, so they can be inspected and replayed later
+type DeadLetter interface {
+	Put(letter *DeadLetterRecord) error
+}
+
+// DeadLetterRecord is a failed INSERT statement of a sub table
+type DeadLetterRecord struct {
+	Time    time.Time     `json:"time"`
+	Table   string        `json:"table"`
+	Stable  string        `json:"stable,omitempty"`
+	Columns []string      `json:"columns"` // Columns written by the statement
+	Error   string        `json:"error"`
+	Rows    []interface{} `json:"rows"`
+}
+
+// FileDeadLetter appends dead letters to a file as JSON lines, one record per line
+type FileDeadLetter struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileDeadLetter opens path for appending, creating it if needed
+func NewFileDeadLetter(path string) (*FileDeadLetter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDeadLetter{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Put appends letter to the file
+func (d *FileDeadLetter) Put(letter *DeadLetterRecord) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.enc.Encode(letter)
+}
+
+// Close closes the file
+func (d *FileDeadLetter) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.file.Close()
+}
+
+// ReplayDeadLetters decodes the JSON lines dead letters of r into rows of
+// type T and writes them again record by record, only the columns of the
+// failed statement are written (NULL included) so rows of Select, Omit or
+// Updates don't overwrite other columns. It returns the number of rows
+// written. Rows failing again are passed to the configured DeadLetter,
+// which should not append to the file being read.
+func ReplayDeadLetters[T any](db *DB, r io.Reader) (int, error) {
+	var letter struct {
+		Table   string            `json:"table"`
+		Columns []string          `json:"columns"`
+		Rows    []json.RawMessage `json:"rows"`
+	}
+
+	written := 0
+	var errs []error
+	dec := json.NewDecoder(r)
+	for {
+		letter.Table, letter.Columns, letter.Rows = "", nil, nil
+		if err := dec.Decode(&letter); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return written, err
+		}
+
+		rows := make([]T, len(letter.Rows))
+		for i, raw := range letter.Rows {
+			if err := json.Unmarshal(raw, &rows[i]); err != nil {
+				return written, fmt.Errorf("dead letter of %s: %w", letter.Table, err)
+			}
+		}
+		tx := db.Table(letter.Table)
+		if len(letter.Columns) > 0 {
+			tx = tx.Select(letter.Columns)
+		}
+		if err := tx.Save(&rows).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		written += len(rows)
+	}
+	return written, errors.Join(errs...)
+}
+
+// deadLetter passes the rows of a failed statement to Config.DeadLetter
+func (db *DB) deadLetter(elements []reflect.Value, schema *Schema, colNames []string, err error) {
+	if db.Config == nil || db.Config.DeadLetter == nil {
+		return
+	}
+	letter := &DeadLetterRecord{
+		Time:    time.Now(),
+		Table:   db.Statement.Table,
+		Columns: colNames,
+		Error:   err.Error(),
+	}
+	if len(schema.Tags) > 0 {
+		letter.Stable = schema.Name
+	}
+	for _, elem := range elements {
+		letter.Rows = append(letter.Rows, reflect.Indirect(elem).Interface())
+	}
+	if err := db.Config.DeadLetter.Put(letter); err != nil {
+		db.AddError(fmt.Errorf("failed to dead letter rows of %s: %w", letter.Table, err))
+	}
+}
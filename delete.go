@@ -0,0 +1,147 @@
+package teorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingWhereClause is returned by Delete and DropSubTables without
+// conditions, unless Config.AllowGlobalDelete is set
+var ErrMissingWhereClause = errors.New("WHERE conditions required, set Config.AllowGlobalDelete to delete everything")
+
+// DropSubTablesBatchSize is the number of sub tables dropped per statement
+var DropSubTablesBatchSize = 100
+
+// Delete deletes the rows matching the conditions from the super table of
+// value (or its table), use Table to delete from a single sub table
+//
+//	db.Where("ts < ?", t).Delete(&Sensor{})
+func (db *DB) Delete(value interface{}) *DB {
+	tx := db.getInstance()
+
+	schema, err := Parse(value)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	tableName := tx.Statement.Table
+	if tableName == "" {
+		tableName = schema.Name
+		if len(schema.Tags) == 0 && schema.TableName != "" {
+			tableName = schema.TableName
+		}
+	}
+
+	if !tx.deleteAllowed() {
+		tx.AddError(ErrMissingWhereClause)
+		return tx
+	}
+
+	whereClause, args := tx.Statement.BuildCondition()
+	sqlStr := tx.explain(fmt.Sprintf("DELETE FROM %s%s", Quote(tableName), whereClause), args...)
+
+	res, err := tx.DB.ExecContext(tx.context(), sqlStr)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	tx.RowsAffected, _ = res.RowsAffected()
+	return tx
+}
+
+// DropSubTables drops the sub tables of the super table of value whose tags
+// match the conditions, RowsAffected is the number of dropped sub tables
+//
+//	db.Where("location = ?", "room_a").DropSubTables(&Sensor{})
+func (db *DB) DropSubTables(value interface{}) *DB {
+	tx := db.getInstance()
+
+	schema, err := Parse(value)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	if len(schema.Tags) == 0 {
+		tx.AddError(fmt.Errorf("%s has no tags, it is not a super table", schema.Name))
+		return tx
+	}
+
+	if !tx.deleteAllowed() {
+		tx.AddError(ErrMissingWhereClause)
+		return tx
+	}
+
+	whereClause, args := tx.Statement.BuildCondition()
+	tableNames, err := tx.subTableNames(schema.Name, whereClause, args)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	if len(tableNames) == 0 {
+		return tx
+	}
+
+	for start := 0; start < len(tableNames); start += DropSubTablesBatchSize {
+		end := start + DropSubTablesBatchSize
+		if end > len(tableNames) {
+			end = len(tableNames)
+		}
+		var clauses []string
+		for _, name := range tableNames[start:end] {
+			clauses = append(clauses, "IF EXISTS "+quoteExact(name))
+		}
+		if err := tx.Exec("DROP TABLE " + strings.Join(clauses, ", ")).Error; err != nil {
+			tx.AddError(err)
+			break
+		}
+	}
+
+	// IF EXISTS skips missing tables silently, count the tables that are gone
+	remaining, err := tx.subTableNames(schema.Name, whereClause, args)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	left := make(map[string]bool, len(remaining))
+	for _, name := range remaining {
+		left[name] = true
+	}
+	for _, name := range tableNames {
+		if !left[name] {
+			tx.RowsAffected++
+		}
+	}
+	return tx
+}
+
+// subTableNames returns the tbname of the sub tables of stable matching
+// the condition, as stored by TDengine
+func (db *DB) subTableNames(stable, whereClause string, args []interface{}) ([]string, error) {
+	sqlStr := db.explain(fmt.Sprintf("SELECT TAGS TBNAME FROM %s%s", Quote(stable), whereClause), args...)
+
+	rows, err := db.DB.QueryContext(db.context(), sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tableNames []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tableNames = append(tableNames, name)
+	}
+	return tableNames, rows.Err()
+}
+
+// deleteAllowed reports whether a delete may run, it requires
+// conditions unless Config.AllowGlobalDelete is set
+func (db *DB) deleteAllowed() bool {
+	if len(db.Statement.Conditions) > 0 {
+		return true
+	}
+	return db.Config != nil && db.Config.AllowGlobalDelete
+}
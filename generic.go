@@ -0,0 +1,92 @@
+package teorm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRecordNotFound is returned by the typed First when no row matches
+var ErrRecordNotFound = errors.New("record not found")
+
+// TypedDB is a query builder for models of type T, returning typed results
+//
+//	sensors, err := teorm.G[Sensor](db).Where("location = ?", "room_a").Order("ts DESC").Find(ctx)
+type TypedDB[T any] struct {
+	db *DB
+}
+
+// G returns a TypedDB querying and writing models of type T through db
+func G[T any](db *DB) *TypedDB[T] {
+	return &TypedDB[T]{db: db}
+}
+
+// Table specifies the queried or written table
+func (g *TypedDB[T]) Table(name string) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Table(name)}
+}
+
+// Where adds a condition, joined to the others with AND
+func (g *TypedDB[T]) Where(query string, args ...interface{}) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Where(query, args...)}
+}
+
+// Select specifies the queried columns, or the columns written by Create
+func (g *TypedDB[T]) Select(columns ...string) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Select(columns)}
+}
+
+// Omit specifies the columns not written by Create
+func (g *TypedDB[T]) Omit(columns ...string) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Omit(columns...)}
+}
+
+// Order specifies the ORDER BY clause
+func (g *TypedDB[T]) Order(value string) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Order(value)}
+}
+
+// Limit limits the number of rows returned
+func (g *TypedDB[T]) Limit(limit int) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Limit(limit)}
+}
+
+// Offset skips rows of the result
+func (g *TypedDB[T]) Offset(offset int) *TypedDB[T] {
+	return &TypedDB[T]{db: g.db.Offset(offset)}
+}
+
+// Find returns the rows matching the conditions
+func (g *TypedDB[T]) Find(ctx context.Context) ([]T, error) {
+	var rows []T
+	err := g.db.WithContext(ctx).Find(&rows).Error
+	return rows, err
+}
+
+// First returns the first row matching the conditions, ErrRecordNotFound
+// when there is none
+func (g *TypedDB[T]) First(ctx context.Context) (T, error) {
+	rows, err := g.Limit(1).Find(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if len(rows) == 0 {
+		var zero T
+		return zero, ErrRecordNotFound
+	}
+	return rows[0], nil
+}
+
+// Count returns the number of rows matching the conditions
+func (g *TypedDB[T]) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(new(T)).Count(&count).Error
+	return count, err
+}
+
+// Create inserts rows, grouped by sub table like DB.Create, and returns
+// the per table report of the write
+func (g *TypedDB[T]) Create(ctx context.Context, rows ...T) (*WriteReport, error) {
+	tx := g.db.WithContext(ctx).Create(&rows)
+	return tx.Report, tx.Error
+}
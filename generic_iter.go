@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package teorm
+
+import (
+	"context"
+	"iter"
+	"reflect"
+)
+
+// Iter returns an iterator over the rows matching the conditions, scanning
+// one row at a time instead of loading the whole result. Iteration stops
+// after the first error, which is yielded with a zero row.
+//
+//	for s, err := range teorm.G[Sensor](db).Where("ts > ?", since).Iter(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		export(s)
+//	}
+func (g *TypedDB[T]) Iter(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		tx := g.db.WithContext(ctx).Model(new(T))
+		rows, err := tx.Rows()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		// Columns are mapped to fields once for all rows
+		modelType := reflect.TypeOf(&zero).Elem()
+		for modelType.Kind() == reflect.Ptr {
+			modelType = modelType.Elem()
+		}
+		scanner, err := tx.rowScannerOf(rows, modelType)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for rows.Next() {
+			var row T
+			// Allocates the struct of pointer types
+			if err := scanner.scan(rows, indirectAlloc(reflect.ValueOf(&row).Elem())); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
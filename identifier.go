@@ -0,0 +1,129 @@
+package teorm
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TDengine naming limits
+const (
+	MaxTableNameLength    = 192
+	MaxColumnNameLength   = 64
+	MaxDatabaseNameLength = 64
+)
+
+// Quote escapes an identifier with backticks, a `db.table` name is quoted
+// per part. Escaped identifiers are case sensitive in TDengine, so names are
+// lowercased first like TDengine does for unescaped names, and tables created
+// before names were escaped keep matching.
+func Quote(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = "`" + strings.ToLower(part) + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteExact escapes a single identifier with backticks as is, for names
+// read back from TDengine like tbname values, which already have their
+// stored case and must not be lowercased or split at dots
+func quoteExact(name string) string {
+	return "`" + name + "`"
+}
+
+// quoteAll quotes every identifier of names
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = Quote(name)
+	}
+	return quoted
+}
+
+// ValidateName checks an identifier against the TDengine naming rules for
+// escaped names: non empty, at most maxLength bytes, printable and without
+// backticks. A `db.table` name is validated per part, any further dot is
+// rejected.
+func ValidateName(name string, maxLength int) error {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid name %q: contains more than one dot", name)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("invalid name %q: empty identifier", name)
+		}
+		if len(part) > maxLength {
+			return fmt.Errorf("invalid name %q: longer than %d bytes", name, maxLength)
+		}
+		for _, r := range part {
+			if r == '`' || !unicode.IsPrint(r) {
+				return fmt.Errorf("invalid name %q: contains %q", name, r)
+			}
+		}
+	}
+	return nil
+}
+
+// HashTableName returns a stable, legal sub table name built from prefix and
+// a hash of the tag values, for tag values that can't be used in names as is
+//
+//	func (s Sensor) TableName() string {
+//		return teorm.HashTableName("sensor", s.Location, s.GroupId)
+//	}
+func HashTableName(prefix string, tags ...interface{}) string {
+	h := sha1.New()
+	for _, tag := range tags {
+		h.Write([]byte(hashString(tag)))
+		h.Write([]byte{0})
+	}
+	sum := hex.EncodeToString(h.Sum(nil))[:16]
+
+	var sb strings.Builder
+	for _, r := range prefix {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	name := sb.String()
+	if max := MaxTableNameLength - len(sum) - 1; len(name) > max {
+		name = name[:max]
+	}
+	if name == "" {
+		return "t_" + sum
+	}
+	return name + "_" + sum
+}
+
+// hashString returns a deterministic string for a tag value
+func hashString(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "NULL"
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "NULL"
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		// String() would include the monotonic clock reading
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// quoteString returns s as a single quoted SQL string literal
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "'", `\'`)
+	return "'" + s + "'"
+}
@@ -0,0 +1,79 @@
+package teorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LastRow finds the latest row matching the conditions into dest, a model
+// or a slice of models with one row per partition. Columns are selected
+// as LAST_ROW(column) AS column so they scan back into the model fields.
+func (db *DB) LastRow(dest interface{}) *DB {
+	return db.latest("LAST_ROW", dest)
+}
+
+// LastNonNull is like LastRow but finds the latest non NULL value of every
+// column, which may come from different rows. LAST uses the last row cache
+// of the database when enabled.
+func (db *DB) LastNonNull(dest interface{}) *DB {
+	return db.latest("LAST", dest)
+}
+
+// FirstRow finds the earliest values matching the conditions into dest, a
+// model or a slice of models with one row per partition, selecting
+// FIRST(column) AS column. Like LAST, FIRST skips NULL values, so the
+// columns may come from different rows.
+func (db *DB) FirstRow(dest interface{}) *DB {
+	return db.latest("FIRST", dest)
+}
+
+// LatestPerTable finds the latest row of every sub table of the super table
+// with its tag values into dest, a pointer to a slice of models
+//
+//	var latest []Sensor
+//	db.Model(&Sensor{}).Where("location = ?", "room_a").LatestPerTable(&latest)
+func (db *DB) LatestPerTable(dest interface{}) *DB {
+	tx := db.getInstance()
+	schema, err := Parse(dest)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	if len(schema.Tags) == 0 {
+		tx.AddError(fmt.Errorf("%s has no tags, LatestPerTable requires a super table", schema.Name))
+		return tx
+	}
+
+	// Tags are constant per sub table, partitioning by them allows selecting them
+	partition := []string{"tbname"}
+	for _, field := range schema.Tags {
+		partition = append(partition, Quote(field.Name))
+	}
+	return tx.Partition(strings.Join(partition, ", ")).LastRow(dest)
+}
+
+// latest finds the result of the selection function fn (LAST_ROW, LAST or
+// FIRST) on every column of dest
+func (db *DB) latest(fn string, dest interface{}) *DB {
+	tx := db.getInstance()
+	schema, err := Parse(dest)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	// Read-only fields aren't table columns, they are left out
+	var selects []string
+	for _, field := range schema.Cols {
+		if field.Readable {
+			selects = append(selects, fmt.Sprintf("%s(%s) AS %s", fn, Quote(field.Name), Quote(field.Name)))
+		}
+	}
+	for _, field := range schema.Tags {
+		if field.Readable {
+			selects = append(selects, Quote(field.Name))
+		}
+	}
+	tx.Statement.Selects = selects
+	return tx.Find(dest)
+}
@@ -0,0 +1,320 @@
+// Package migrate applies versioned migrations to a TDengine database and
+// records them in a history table managed by teorm.
+//
+// TDengine has no transactions, so concurrent runs are serialized with a
+// lease row written to a lock table: contenders write a row, wait
+// Options.LockSettle for the other writes to land, and the owner of the
+// latest row takes the lock. The lease expires after Options.LockTTL. This
+// holds as long as a contender writes its row within LockSettle of reading
+// the lock table.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	teorm "github.com/enterShuIoT/teorm"
+)
+
+// Migration is a versioned schema change, applied in registration order
+type Migration struct {
+	ID   string
+	Up   func(*teorm.DB) error
+	Down func(*teorm.DB) error
+}
+
+// Options configures the history and lock tables
+type Options struct {
+	// TableName is the history table, default "teorm_migrations"
+	TableName string
+	// LockTableName is the lease table, default "teorm_migration_locks"
+	LockTableName string
+	// LockTTL is how long a lease is held before others may take it over, default 5 minutes
+	LockTTL time.Duration
+	// Owner identifies this process in the lease table, default hostname and pid
+	Owner string
+	// LockSettle is how long to wait for concurrent lease writes before
+	// reading back the winner, default 2 seconds
+	LockSettle time.Duration
+}
+
+// DefaultOptions are used when New is given nil options
+var DefaultOptions = &Options{
+	TableName:     "teorm_migrations",
+	LockTableName: "teorm_migration_locks",
+	LockTTL:       5 * time.Minute,
+	LockSettle:    2 * time.Second,
+}
+
+// ErrLocked is returned when another owner holds the migration lease
+var ErrLocked = errors.New("migrate: migrations are locked by another process")
+
+// ErrNoMigration is returned by RollbackLast when nothing is applied
+var ErrNoMigration = errors.New("migrate: no applied migration to roll back")
+
+// Status reports whether a migration is applied
+type Status struct {
+	ID        string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// historyRecord is a row of the history table, an append-only log of up
+// and down actions since TDengine can only delete rows by timestamp
+type historyRecord struct {
+	Ts          time.Time `teorm:"primaryKey"`
+	MigrationID string    `teorm:"size:255"`
+	Action      string    `teorm:"size:16"`
+}
+
+// lockRecord is a row of the lock table, the latest row holds the lease
+type lockRecord struct {
+	Ts        time.Time `teorm:"primaryKey"`
+	Owner     string    `teorm:"size:255"`
+	ExpiresAt time.Time
+}
+
+const (
+	actionUp   = "up"
+	actionDown = "down"
+)
+
+// Migrator runs migrations against a database
+type Migrator struct {
+	db         *teorm.DB
+	options    Options
+	migrations []*Migration
+
+	mu     sync.Mutex
+	lastTs time.Time
+}
+
+// New returns a Migrator for migrations
+func New(db *teorm.DB, options *Options, migrations []*Migration) *Migrator {
+	if options == nil {
+		options = DefaultOptions
+	}
+	opts := *options
+	if opts.TableName == "" {
+		opts.TableName = DefaultOptions.TableName
+	}
+	if opts.LockTableName == "" {
+		opts.LockTableName = DefaultOptions.LockTableName
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = DefaultOptions.LockTTL
+	}
+	if opts.LockSettle <= 0 {
+		opts.LockSettle = DefaultOptions.LockSettle
+	}
+	if opts.Owner == "" {
+		hostname, _ := os.Hostname()
+		opts.Owner = fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+	}
+	return &Migrator{db: db, options: opts, migrations: migrations}
+}
+
+// Migrate applies all pending migrations in order
+func (m *Migrator) Migrate() error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	return m.withLock(func() error {
+		applied, err := m.applied()
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.ID]; ok {
+				continue
+			}
+			if migration.Up == nil {
+				return fmt.Errorf("migrate: migration %s has no Up function", migration.ID)
+			}
+			if err := migration.Up(m.db); err != nil {
+				return fmt.Errorf("migrate: migration %s failed: %w", migration.ID, err)
+			}
+			if err := m.record(migration.ID, actionUp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RollbackLast reverts the most recently applied migration
+func (m *Migrator) RollbackLast() error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	return m.withLock(func() error {
+		applied, err := m.applied()
+		if err != nil {
+			return err
+		}
+
+		var last *Migration
+		var lastAt time.Time
+		for _, migration := range m.migrations {
+			if at, ok := applied[migration.ID]; ok && !at.Before(lastAt) {
+				last, lastAt = migration, at
+			}
+		}
+		if last == nil {
+			return ErrNoMigration
+		}
+		if last.Down == nil {
+			return fmt.Errorf("migrate: migration %s has no Down function", last.ID)
+		}
+		if err := last.Down(m.db); err != nil {
+			return fmt.Errorf("migrate: rollback of %s failed: %w", last.ID, err)
+		}
+		return m.record(last.ID, actionDown)
+	})
+}
+
+// Status returns the state of every registered migration
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.createTables(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		at, ok := applied[migration.ID]
+		statuses = append(statuses, Status{ID: migration.ID, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) validate() error {
+	seen := make(map[string]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		if migration.ID == "" {
+			return errors.New("migrate: migration ID is required")
+		}
+		if seen[migration.ID] {
+			return fmt.Errorf("migrate: duplicated migration ID %s", migration.ID)
+		}
+		seen[migration.ID] = true
+	}
+	return nil
+}
+
+func (m *Migrator) createTables() error {
+	if err := m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (ts TIMESTAMP, migration_id VARCHAR(255), action VARCHAR(16))", teorm.Quote(m.options.TableName))).Error; err != nil {
+		return fmt.Errorf("migrate: failed to create history table: %w", err)
+	}
+	if err := m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (ts TIMESTAMP, owner VARCHAR(255), expires_at TIMESTAMP)", teorm.Quote(m.options.LockTableName))).Error; err != nil {
+		return fmt.Errorf("migrate: failed to create lock table: %w", err)
+	}
+	return nil
+}
+
+// applied replays the history log and returns applied IDs with their time
+func (m *Migrator) applied() (map[string]time.Time, error) {
+	var records []historyRecord
+	if err := m.db.Table(m.options.TableName).Order("ts ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("migrate: failed to read history: %w", err)
+	}
+	applied := make(map[string]time.Time)
+	for _, record := range records {
+		switch record.Action {
+		case actionUp:
+			applied[record.MigrationID] = record.Ts
+		case actionDown:
+			delete(applied, record.MigrationID)
+		}
+	}
+	return applied, nil
+}
+
+func (m *Migrator) record(id, action string) error {
+	record := historyRecord{Ts: m.now(), MigrationID: id, Action: action}
+	if err := m.db.Table(m.options.TableName).Create(&record).Error; err != nil {
+		return fmt.Errorf("migrate: failed to record %s of %s: %w", action, id, err)
+	}
+	return nil
+}
+
+// withLock runs fc while holding the migration lease
+func (m *Migrator) withLock(fc func() error) error {
+	if err := m.createTables(); err != nil {
+		return err
+	}
+	if err := m.lock(); err != nil {
+		return err
+	}
+	err := fc()
+	if unlockErr := m.unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+func (m *Migrator) latestLock() (*lockRecord, error) {
+	var records []lockRecord
+	if err := m.db.Table(m.options.LockTableName).Order("ts DESC").Limit(1).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("migrate: failed to read lock: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
+
+func (m *Migrator) lock() error {
+	current, err := m.latestLock()
+	if err != nil {
+		return err
+	}
+	if current != nil && current.Owner != m.options.Owner && time.Now().Before(current.ExpiresAt) {
+		return ErrLocked
+	}
+
+	ts := m.now()
+	lease := lockRecord{Ts: ts, Owner: m.options.Owner, ExpiresAt: ts.Add(m.options.LockTTL)}
+	if err := m.db.Table(m.options.LockTableName).Create(&lease).Error; err != nil {
+		return fmt.Errorf("migrate: failed to write lock: %w", err)
+	}
+
+	// Contenders that saw no lease write within the settle delay, after it
+	// all of them read the same latest row and agree on a single winner
+	time.Sleep(m.options.LockSettle)
+	current, err = m.latestLock()
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Owner != m.options.Owner {
+		return ErrLocked
+	}
+	return nil
+}
+
+func (m *Migrator) unlock() error {
+	ts := m.now()
+	lease := lockRecord{Ts: ts, Owner: m.options.Owner, ExpiresAt: ts}
+	if err := m.db.Table(m.options.LockTableName).Create(&lease).Error; err != nil {
+		return fmt.Errorf("migrate: failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// now returns a strictly increasing timestamp with millisecond resolution,
+// rows with the same timestamp would overwrite each other
+func (m *Migrator) now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ts := time.Now().Truncate(time.Millisecond)
+	if !ts.After(m.lastTs) {
+		ts = m.lastTs.Add(time.Millisecond)
+	}
+	m.lastTs = ts
+	return ts
+}
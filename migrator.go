@@ -2,42 +2,616 @@ package teorm
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func (db *DB) AutoMigrate(dst interface{}) error {
-	schema := Parse(dst)
-	
+// Migrator manages super tables and tables from model definitions
+type Migrator interface {
+	AutoMigrate(dst ...interface{}) error
+	HasTable(dst interface{}) bool
+	HasColumn(dst interface{}, field string) bool
+	HasTag(dst interface{}, field string) bool
+	ColumnTypes(dst interface{}) ([]ColumnType, error)
+
+	AddColumn(dst interface{}, field string) error
+	DropColumn(dst interface{}, field string) error
+	ModifyColumnLength(dst interface{}, field string) error
+	RenameColumn(dst interface{}, oldName, field string) error
+	AddTag(dst interface{}, field string) error
+	DropTag(dst interface{}, field string) error
+	RenameTag(dst interface{}, oldName, field string) error
+	DropTable(dst ...interface{}) error
+	DropStable(dst ...interface{}) error
+
+	CreateDatabase(name string, options DatabaseOptions) error
+	AlterDatabase(name string, options DatabaseOptions) error
+	DropDatabase(name string) error
+	HasDatabase(name string) bool
+}
+
+// ColumnType describes a column or tag of a live table, as returned by DESCRIBE
+type ColumnType struct {
+	Name         string
+	DatabaseType string // e.g. VARCHAR, INT UNSIGNED
+	Length       int64
+	IsTag        bool
+}
+
+// FullType returns the type with its length for variable length types, e.g. VARCHAR(64)
+func (c ColumnType) FullType() string {
+	if isVarLenType(c.DatabaseType) {
+		return fmt.Sprintf("%s(%d)", c.DatabaseType, c.Length)
+	}
+	return c.DatabaseType
+}
+
+type migrator struct {
+	db *DB
+}
+
+// Migrator returns the migrator of db
+func (db *DB) Migrator() Migrator {
+	return migrator{db: db.getInstance()}
+}
+
+// AutoMigrate creates or updates the super tables (or tables) of dst
+func (db *DB) AutoMigrate(dst ...interface{}) error {
+	return db.Migrator().AutoMigrate(dst...)
+}
+
+func (m migrator) exec(sql string) error {
+	_, err := m.db.DB.Exec(sql)
+	return err
+}
+
+// tableName returns the name of the table managed for value, a string is used as is
+func (m migrator) tableName(value interface{}) (string, *Schema, error) {
+	if name, ok := value.(string); ok {
+		return name, nil, nil
+	}
+	schema, err := Parse(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return schema.Name, schema, nil
+}
+
+// tableKind returns STABLE for super tables and TABLE for normal tables
+func tableKind(schema *Schema) string {
+	if len(schema.Tags) > 0 {
+		return "STABLE"
+	}
+	return "TABLE"
+}
+
+func (m migrator) AutoMigrate(dst ...interface{}) error {
+	for _, value := range dst {
+		schema, err := Parse(value)
+		if err != nil {
+			return err
+		}
+		if err := schema.checkDataTypes(); err != nil {
+			return err
+		}
+		if err := ValidateName(schema.Name, MaxTableNameLength); err != nil {
+			return err
+		}
+
+		if !m.HasTable(schema.Name) {
+			if err := m.exec(createTableSQL(schema)); err != nil {
+				return fmt.Errorf("failed to create stable %s: %w", schema.Name, err)
+			}
+			continue
+		}
+
+		if err := m.migrateTable(schema); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", schema.Name, err)
+		}
+	}
+	return nil
+}
+
+func createTableSQL(schema *Schema) string {
 	// Build CREATE STABLE statement
 	// CREATE STABLE IF NOT EXISTS name (cols) TAGS (tags)
-	
+
 	var colDefs []string
 	for _, field := range schema.Cols {
-		colDefs = append(colDefs, fmt.Sprintf("%s %s", field.Name, field.Type))
+		colDefs = append(colDefs, columnDefinition(field))
 	}
-	
+
 	var tagDefs []string
 	for _, field := range schema.Tags {
-		tagDefs = append(tagDefs, fmt.Sprintf("%s %s", field.Name, field.Type))
+		tagDefs = append(tagDefs, fmt.Sprintf("%s %s", Quote(field.Name), field.Type))
 	}
-	
+
 	var sql string
 	if len(tagDefs) > 0 {
 		sql = fmt.Sprintf("CREATE STABLE IF NOT EXISTS %s (%s) TAGS (%s)",
-			schema.Name,
+			Quote(schema.Name),
 			strings.Join(colDefs, ", "),
 			strings.Join(tagDefs, ", "))
 	} else {
 		sql = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)",
-			schema.Name,
+			Quote(schema.Name),
 			strings.Join(colDefs, ", "))
 	}
 
-	fmt.Printf("[DEBUG] AutoMigrate SQL: %s\n", sql)
+	if options := tableOptions(schema.Options); len(options) > 0 {
+		sql += " " + strings.Join(options, " ")
+	}
+	return sql
+}
+
+// columnDefinition returns the column definition with the TDengine 3.3
+// ENCODE, COMPRESS and LEVEL options of the field
+func columnDefinition(field *Field) string {
+	def := fmt.Sprintf("%s %s", Quote(field.Name), field.Type)
+	if field.IsTag {
+		return def
+	}
+	for _, option := range []string{"ENCODE", "COMPRESS", "LEVEL"} {
+		if val, ok := field.TagSettings[option]; ok && val != option {
+			def += fmt.Sprintf(" %s '%s'", option, val)
+		}
+	}
+	return def
+}
+
+func tableOptions(options StableOptions) []string {
+	var clauses []string
+	if options.Comment != "" {
+		clauses = append(clauses, "COMMENT "+formatTagValue(options.Comment, PrecisionMilli))
+	}
+	if options.Watermark != "" {
+		clauses = append(clauses, "WATERMARK "+options.Watermark)
+	}
+	if options.MaxDelay != "" {
+		clauses = append(clauses, "MAX_DELAY "+options.MaxDelay)
+	}
+	if len(options.Rollup) > 0 {
+		clauses = append(clauses, fmt.Sprintf("ROLLUP(%s)", strings.Join(options.Rollup, ", ")))
+	}
+	if len(options.SMA) > 0 {
+		clauses = append(clauses, fmt.Sprintf("SMA(%s)", strings.Join(quoteAll(options.SMA), ", ")))
+	}
+	if options.TTL > 0 {
+		clauses = append(clauses, fmt.Sprintf("TTL %d", options.TTL))
+	}
+	return clauses
+}
+
+// migrateTable diffs the schema against the live table. Missing columns and
+// tags are added and variable length types widened; type changes and removed
+// fields are only applied when Config.AllowDestructiveMigration is set.
+func (m migrator) migrateTable(schema *Schema) error {
+	columnTypes, err := m.ColumnTypes(schema.Name)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]ColumnType, len(columnTypes))
+	for _, ct := range columnTypes {
+		live[strings.ToLower(ct.Name)] = ct
+	}
+
+	allowDestructive := m.db.Config != nil && m.db.Config.AllowDestructiveMigration
+	kind := tableKind(schema)
+	declared := make(map[string]bool)
+
+	for _, field := range append(append([]*Field{}, schema.Cols...), schema.Tags...) {
+		declared[strings.ToLower(field.Name)] = true
+		target := "COLUMN"
+		if field.IsTag {
+			target = "TAG"
+		}
+
+		ct, ok := live[strings.ToLower(field.Name)]
+		if !ok {
+			if err := m.exec(fmt.Sprintf("ALTER %s %s ADD %s %s", kind, Quote(schema.Name), target, columnDefinition(field))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		liveType, liveLength := ct.DatabaseType, ct.Length
+		wantType, wantLength := splitDataType(field.Type)
+		if ct.IsTag == field.IsTag && liveType == wantType && (!isVarLenType(wantType) || liveLength == wantLength) {
+			continue
+		}
+		if ct.IsTag == field.IsTag && liveType != wantType && isLegacyColumn(field, liveType, liveLength) {
+			continue
+		}
+
+		// Widening a variable length type is safe
+		if ct.IsTag == field.IsTag && liveType == wantType && wantLength > liveLength {
+			if err := m.exec(fmt.Sprintf("ALTER %s %s MODIFY %s %s %s", kind, Quote(schema.Name), target, Quote(field.Name), field.Type)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.IsPrimaryKey || !allowDestructive {
+			return fmt.Errorf("column %s changed from %s to %s, refusing destructive migration", field.Name, ct.FullType(), field.Type)
+		}
+		if err := m.dropColumnType(schema, ct); err != nil {
+			return err
+		}
+		if err := m.exec(fmt.Sprintf("ALTER %s %s ADD %s %s", kind, Quote(schema.Name), target, columnDefinition(field))); err != nil {
+			return err
+		}
+	}
+
+	if allowDestructive {
+		for _, ct := range columnTypes {
+			if !declared[strings.ToLower(ct.Name)] {
+				if err := m.dropColumnType(schema, ct); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m migrator) dropColumnType(schema *Schema, ct ColumnType) error {
+	target := "COLUMN"
+	if ct.IsTag {
+		target = "TAG"
+	}
+	return m.exec(fmt.Sprintf("ALTER %s %s DROP %s %s", tableKind(schema), Quote(schema.Name), target, Quote(ct.Name)))
+}
+
+// lookUpField parses dst and finds field by Go field name or column name
+func (m migrator) lookUpField(dst interface{}, field string) (*Schema, *Field, error) {
+	schema, err := Parse(dst)
+	if err != nil {
+		return nil, nil, err
+	}
+	f := schema.LookUpField(field)
+	if f == nil {
+		return nil, nil, fmt.Errorf("failed to look up field %s of %s", field, schema.Name)
+	}
+	return schema, f, nil
+}
+
+// columnName returns the column name of field, used as is when the model
+// no longer declares it (e.g. dropping a removed field)
+func (m migrator) columnName(dst interface{}, field string) (*Schema, string, error) {
+	schema, err := Parse(dst)
+	if err != nil {
+		return nil, "", err
+	}
+	if f := schema.LookUpField(field); f != nil {
+		return schema, f.Name, nil
+	}
+	return schema, field, nil
+}
+
+func (m migrator) AddColumn(dst interface{}, field string) error {
+	schema, f, err := m.lookUpField(dst, field)
+	if err != nil {
+		return err
+	}
+	if f.IsTag {
+		return fmt.Errorf("field %s is a tag, use AddTag", field)
+	}
+	if f.dataTypeErr != nil {
+		return f.dataTypeErr
+	}
+	return m.exec(fmt.Sprintf("ALTER %s %s ADD COLUMN %s", tableKind(schema), Quote(schema.Name), columnDefinition(f)))
+}
+
+func (m migrator) DropColumn(dst interface{}, field string) error {
+	schema, name, err := m.columnName(dst, field)
+	if err != nil {
+		return err
+	}
+	return m.exec(fmt.Sprintf("ALTER %s %s DROP COLUMN %s", tableKind(schema), Quote(schema.Name), Quote(name)))
+}
+
+// ModifyColumnLength widens a VARCHAR, NCHAR or VARBINARY column or tag to
+// the length declared by the model
+func (m migrator) ModifyColumnLength(dst interface{}, field string) error {
+	schema, f, err := m.lookUpField(dst, field)
+	if err != nil {
+		return err
+	}
+	if f.dataTypeErr != nil {
+		return f.dataTypeErr
+	}
+	if dataType, _ := splitDataType(f.Type); !isVarLenType(dataType) {
+		return fmt.Errorf("field %s has type %s, only variable length types can be modified", field, f.Type)
+	}
+	target := "COLUMN"
+	if f.IsTag {
+		target = "TAG"
+	}
+	return m.exec(fmt.Sprintf("ALTER %s %s MODIFY %s %s %s", tableKind(schema), Quote(schema.Name), target, Quote(f.Name), f.Type))
+}
+
+// RenameColumn renames column oldName to the column of field, only
+// supported by normal tables
+func (m migrator) RenameColumn(dst interface{}, oldName, field string) error {
+	schema, f, err := m.lookUpField(dst, field)
+	if err != nil {
+		return err
+	}
+	if f.IsTag {
+		return m.RenameTag(dst, oldName, field)
+	}
+	if len(schema.Tags) > 0 {
+		return fmt.Errorf("renaming columns of super table %s is not supported by TDengine", schema.Name)
+	}
+	return m.exec(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s %s", Quote(schema.Name), Quote(oldName), Quote(f.Name)))
+}
+
+func (m migrator) AddTag(dst interface{}, field string) error {
+	schema, f, err := m.lookUpField(dst, field)
+	if err != nil {
+		return err
+	}
+	if !f.IsTag {
+		return fmt.Errorf("field %s is not a tag, use AddColumn", field)
+	}
+	if f.dataTypeErr != nil {
+		return f.dataTypeErr
+	}
+	return m.exec(fmt.Sprintf("ALTER STABLE %s ADD TAG %s %s", Quote(schema.Name), Quote(f.Name), f.Type))
+}
 
-	if _, err := db.DB.Exec(sql); err != nil {
-		return fmt.Errorf("failed to create stable %s: %w", schema.Name, err)
+func (m migrator) DropTag(dst interface{}, field string) error {
+	schema, name, err := m.columnName(dst, field)
+	if err != nil {
+		return err
+	}
+	return m.exec(fmt.Sprintf("ALTER STABLE %s DROP TAG %s", Quote(schema.Name), Quote(name)))
+}
+
+// RenameTag renames tag oldName to the tag of field
+func (m migrator) RenameTag(dst interface{}, oldName, field string) error {
+	schema, f, err := m.lookUpField(dst, field)
+	if err != nil {
+		return err
+	}
+	if !f.IsTag {
+		return fmt.Errorf("field %s is not a tag", field)
+	}
+	return m.exec(fmt.Sprintf("ALTER STABLE %s RENAME TAG %s %s", Quote(schema.Name), Quote(oldName), Quote(f.Name)))
+}
+
+// DropTable drops tables, a model drops the sub table named by its
+// TableName() (or its normal table), a string is used as the table name
+func (m migrator) DropTable(dst ...interface{}) error {
+	for _, value := range dst {
+		name, ok := value.(string)
+		if !ok {
+			schema, err := Parse(value)
+			if err != nil {
+				return err
+			}
+			name = schema.TableName
+			if name == "" {
+				name = schema.Name
+			}
+		}
+		if err := m.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", Quote(name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropStable drops super tables with all their sub tables
+func (m migrator) DropStable(dst ...interface{}) error {
+	for _, value := range dst {
+		name, _, err := m.tableName(value)
+		if err != nil {
+			return err
+		}
+		if err := m.exec(fmt.Sprintf("DROP STABLE IF EXISTS %s", Quote(name))); err != nil {
+			return err
+		}
 	}
-	
 	return nil
 }
+
+func (m migrator) HasTable(dst interface{}) bool {
+	name, _, err := m.tableName(dst)
+	if err != nil {
+		return false
+	}
+	for _, sql := range []string{"SHOW STABLES LIKE %s", "SHOW TABLES LIKE %s"} {
+		names, err := m.queryStrings(fmt.Sprintf(sql, formatTagValue(strings.ToLower(name), m.db.precision())))
+		if err != nil {
+			continue
+		}
+		// LIKE treats `_` as a wildcard, compare exact names
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m migrator) HasColumn(dst interface{}, field string) bool {
+	ct, ok := m.lookUpColumnType(dst, field)
+	return ok && !ct.IsTag
+}
+
+func (m migrator) HasTag(dst interface{}, field string) bool {
+	ct, ok := m.lookUpColumnType(dst, field)
+	return ok && ct.IsTag
+}
+
+// lookUpColumnType finds the live column of field, a Go field name or column name
+func (m migrator) lookUpColumnType(dst interface{}, field string) (ColumnType, bool) {
+	name := field
+	if _, schema, err := m.tableName(dst); err == nil && schema != nil {
+		if f := schema.LookUpField(field); f != nil {
+			name = f.Name
+		}
+	}
+	columnTypes, err := m.ColumnTypes(dst)
+	if err != nil {
+		return ColumnType{}, false
+	}
+	for _, ct := range columnTypes {
+		if strings.EqualFold(ct.Name, name) {
+			return ct, true
+		}
+	}
+	return ColumnType{}, false
+}
+
+func (m migrator) ColumnTypes(dst interface{}) ([]ColumnType, error) {
+	name, _, err := m.tableName(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.DB.Query("DESCRIBE " + Quote(name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var columnTypes []ColumnType
+	for rows.Next() {
+		// field, type, length, note [, encode, compress, level]
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+
+		var ct ColumnType
+		for i, col := range columns {
+			switch strings.ToLower(col) {
+			case "field":
+				ct.Name = fmt.Sprint(values[i])
+			case "type":
+				ct.DatabaseType, _ = splitDataType(fmt.Sprint(values[i]))
+			case "length":
+				ct.Length, _ = strconv.ParseInt(fmt.Sprint(values[i]), 10, 64)
+			case "note":
+				ct.IsTag = strings.EqualFold(fmt.Sprint(values[i]), "TAG")
+			}
+		}
+		columnTypes = append(columnTypes, ct)
+	}
+	return columnTypes, rows.Err()
+}
+
+// queryStrings returns the first column of every row
+func (m migrator) queryStrings(sql string) ([]string, error) {
+	rows, err := m.db.DB.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+		result = append(result, fmt.Sprint(values[0]))
+	}
+	return result, rows.Err()
+}
+
+// normalizeDataType upper cases a type name and maps BINARY to VARCHAR
+func normalizeDataType(dataType string) string {
+	dataType = strings.ToUpper(strings.Join(strings.Fields(dataType), " "))
+	if dataType == "BINARY" {
+		return "VARCHAR"
+	}
+	return dataType
+}
+
+// splitDataType splits a declared type such as VARCHAR(64) into its name and length
+func splitDataType(dataType string) (string, int64) {
+	dataType = normalizeDataType(dataType)
+	open := strings.Index(dataType, "(")
+	if open < 0 || !strings.HasSuffix(dataType, ")") {
+		return dataType, 0
+	}
+	name := normalizeDataType(dataType[:open])
+	if !isVarLenType(name) {
+		// e.g. DECIMAL(10, 2), compared as a whole
+		return strings.ReplaceAll(dataType, " ", ""), 0
+	}
+	length, _ := strconv.ParseInt(strings.TrimSpace(dataType[open+1:len(dataType)-1]), 10, 64)
+	return name, length
+}
+
+func isVarLenType(dataType string) bool {
+	switch dataType {
+	case "VARCHAR", "BINARY", "NCHAR", "VARBINARY", "GEOMETRY":
+		return true
+	}
+	return false
+}
+
+// isLegacyColumn reports whether liveType is the column created for field
+// by the type mapping used before sized and narrow types were introduced,
+// e.g. INT for int8 or BINARY(64) for []byte. Such columns are kept as
+// changing them is destructive.
+func isLegacyColumn(field *Field, liveType string, liveLength int64) bool {
+	if _, ok := field.TagSettings["TYPE"]; ok {
+		return false
+	}
+	legacyType, legacyLength := splitDataType(legacyDataTypeOf(field.FieldType))
+	return liveType == legacyType && (!isVarLenType(legacyType) || liveLength == legacyLength)
+}
+
+// legacyDataTypeOf returns the data type the old mapping created for t
+func legacyDataTypeOf(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOL"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return "INT"
+	case reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "INT UNSIGNED"
+	case reflect.Uint64:
+		return "BIGINT UNSIGNED"
+	case reflect.Float32:
+		return "FLOAT"
+	case reflect.Float64:
+		return "DOUBLE"
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return "TIMESTAMP"
+		}
+	}
+	return "BINARY(64)"
+}
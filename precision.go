@@ -0,0 +1,123 @@
+package teorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Timestamp precisions supported by TDengine
+const (
+	PrecisionMilli = "ms"
+	PrecisionMicro = "us"
+	PrecisionNano  = "ns"
+)
+
+func validPrecision(precision string) bool {
+	switch precision {
+	case PrecisionMilli, PrecisionMicro, PrecisionNano:
+		return true
+	}
+	return false
+}
+
+// precision returns the configured timestamp precision
+func (db *DB) precision() string {
+	if db.Config == nil || db.Config.Precision == "" {
+		return PrecisionMilli
+	}
+	return db.Config.Precision
+}
+
+// queryPrecision returns the precision of the current database, or
+// milliseconds (TDengine default) when no database is selected
+func queryPrecision(db *sql.DB) (string, error) {
+	var name sql.NullString
+	if err := db.QueryRow("SELECT DATABASE()").Scan(&name); err != nil {
+		return "", err
+	}
+	if !name.Valid || name.String == "" {
+		return PrecisionMilli, nil
+	}
+
+	var precision string
+	sqlStr := fmt.Sprintf("SELECT `precision` FROM information_schema.ins_databases WHERE name = %s", formatTagValue(name.String, PrecisionMilli))
+	if err := db.QueryRow(sqlStr).Scan(&precision); err != nil {
+		return "", err
+	}
+	return precision, nil
+}
+
+// TimeToEpoch converts t to an epoch integer in the given precision
+func TimeToEpoch(t time.Time, precision string) int64 {
+	switch precision {
+	case PrecisionMicro:
+		return t.UnixMicro()
+	case PrecisionNano:
+		return t.UnixNano()
+	default:
+		return t.UnixMilli()
+	}
+}
+
+// EpochToTime converts an epoch integer in the given precision to time.Time
+func EpochToTime(epoch int64, precision string) time.Time {
+	switch precision {
+	case PrecisionMicro:
+		return time.UnixMicro(epoch)
+	case PrecisionNano:
+		return time.Unix(0, epoch)
+	default:
+		return time.UnixMilli(epoch)
+	}
+}
+
+// epochScanner scans a TIMESTAMP column into an integer field holding the
+// epoch in the database precision
+type epochScanner struct {
+	dst       reflect.Value
+	precision string
+}
+
+func (s *epochScanner) Scan(src interface{}) error {
+	if setNil(s.dst, src) {
+		return nil
+	}
+	var epoch int64
+	switch v := src.(type) {
+	case time.Time:
+		epoch = TimeToEpoch(v, s.precision)
+	case int64:
+		epoch = v
+	default:
+		return fmt.Errorf("failed to scan %#v into epoch timestamp", src)
+	}
+	dst := indirectAlloc(s.dst)
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int64:
+		dst.SetInt(epoch)
+	case reflect.Uint, reflect.Uint64:
+		dst.SetUint(uint64(epoch))
+	default:
+		return fmt.Errorf("invalid field type %s for epoch timestamp", dst.Type())
+	}
+	return nil
+}
+
+// isEpochField reports whether field stores a TIMESTAMP in an integer
+func isEpochField(field *Field) bool {
+	if !strings.EqualFold(field.Type, "TIMESTAMP") || field.Serializer != nil {
+		return false
+	}
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return true
+	}
+	return false
+}
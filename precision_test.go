@@ -0,0 +1,95 @@
+package teorm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nanoTime has a non zero nanosecond part to detect truncation
+var nanoTime = time.Date(2024, 5, 17, 8, 30, 15, 123456789, time.UTC)
+
+func TestTimeToEpoch(t *testing.T) {
+	tests := []struct {
+		precision string
+		epoch     int64
+		roundTrip time.Time
+	}{
+		{PrecisionMilli, 1715934615123, nanoTime.Truncate(time.Millisecond)},
+		{PrecisionMicro, 1715934615123456, nanoTime.Truncate(time.Microsecond)},
+		{PrecisionNano, 1715934615123456789, nanoTime},
+	}
+	for _, tt := range tests {
+		epoch := TimeToEpoch(nanoTime, tt.precision)
+		if epoch != tt.epoch {
+			t.Errorf("TimeToEpoch(%s) = %d, want %d", tt.precision, epoch, tt.epoch)
+		}
+		if got := EpochToTime(epoch, tt.precision); !got.Equal(tt.roundTrip) {
+			t.Errorf("EpochToTime(%s) = %s, want %s", tt.precision, got, tt.roundTrip)
+		}
+	}
+}
+
+func TestFormatTagValueTime(t *testing.T) {
+	tests := map[string]string{
+		PrecisionMilli: "1715934615123",
+		PrecisionMicro: "1715934615123456",
+		PrecisionNano:  "1715934615123456789",
+	}
+	for precision, want := range tests {
+		if got := formatTagValue(nanoTime, precision); got != want {
+			t.Errorf("formatTagValue(%s) = %s, want %s", precision, got, want)
+		}
+		if got := formatTagValue(&nanoTime, precision); got != want {
+			t.Errorf("formatTagValue(%s) of pointer = %s, want %s", precision, got, want)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := map[string]string{
+		PrecisionMilli: "SELECT * FROM `t` WHERE ts > 1715934615123 AND name = 'it\\'s' AND note = '?'",
+		PrecisionMicro: "SELECT * FROM `t` WHERE ts > 1715934615123456 AND name = 'it\\'s' AND note = '?'",
+		PrecisionNano:  "SELECT * FROM `t` WHERE ts > 1715934615123456789 AND name = 'it\\'s' AND note = '?'",
+	}
+	for precision, want := range tests {
+		db := &DB{Config: &Config{Precision: precision}, Statement: &Statement{}}
+		got := db.explain("SELECT * FROM `t` WHERE ts > ? AND name = ? AND note = '?'", nanoTime, "it's")
+		if got != want {
+			t.Errorf("explain(%s) = %s, want %s", precision, got, want)
+		}
+	}
+}
+
+func TestEpochScanner(t *testing.T) {
+	units := map[string]time.Duration{
+		PrecisionMilli: time.Millisecond,
+		PrecisionMicro: time.Microsecond,
+		PrecisionNano:  time.Nanosecond,
+	}
+	for precision, unit := range units {
+		var epoch int64
+		scanner := &epochScanner{dst: reflect.ValueOf(&epoch).Elem(), precision: precision}
+		if err := scanner.Scan(nanoTime); err != nil {
+			t.Fatalf("Scan(%s): %v", precision, err)
+		}
+		if want := TimeToEpoch(nanoTime, precision); epoch != want {
+			t.Errorf("Scan(%s) = %d, want %d", precision, epoch, want)
+		}
+		if got, want := EpochToTime(epoch, precision), nanoTime.Truncate(unit); !got.Equal(want) {
+			t.Errorf("round trip (%s) = %s, want %s", precision, got, want)
+		}
+	}
+
+	epoch := new(int64)
+	scanner := &epochScanner{dst: reflect.ValueOf(&epoch).Elem(), precision: PrecisionNano}
+	if err := scanner.Scan(nil); err != nil || epoch != nil {
+		t.Errorf("Scan(nil) = %v, %v, want nil pointer", epoch, err)
+	}
+	if err := scanner.Scan(int64(42)); err != nil || epoch == nil || *epoch != 42 {
+		t.Errorf("Scan(42) = %v, %v, want 42", epoch, err)
+	}
+	if err := scanner.Scan("x"); err == nil {
+		t.Error("Scan(string) succeeded, want error")
+	}
+}
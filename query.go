@@ -1,6 +1,7 @@
 package teorm
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
@@ -8,51 +9,13 @@ import (
 
 func (db *DB) Find(dest interface{}) *DB {
 	tx := db.getInstance()
-	schema := Parse(dest)
-
-	tableName := tx.Statement.Table
-	if tableName == "" {
-		if len(schema.Tags) > 0 {
-			// If it's a super table (has tags), query from super table by default
-			tableName = schema.Name
-		} else if schema.TableName != "" {
-			tableName = schema.TableName
-		} else {
-			tableName = schema.Name
-		}
-	}
-
-	// Build Select
-	selectClause := "*"
-	if len(tx.Statement.Selects) > 0 {
-		selectClause = strings.Join(tx.Statement.Selects, ", ")
-	}
-
-	// Build Where
-	whereClause, args := tx.Statement.BuildCondition()
-
-	sql := fmt.Sprintf("SELECT %s FROM %s%s", selectClause, tableName, whereClause)
-
-	if tx.Statement.Order != "" {
-		sql += " ORDER BY " + tx.Statement.Order
-	}
-
-	if tx.Statement.LimitVal > 0 {
-		sql += fmt.Sprintf(" LIMIT %d", tx.Statement.LimitVal)
-	}
-
-	if tx.Statement.OffsetVal > 0 {
-		sql += fmt.Sprintf(" OFFSET %d", tx.Statement.OffsetVal)
-	}
-
-	if tx.Statement.Group != "" {
-		sql += " GROUP BY " + tx.Statement.Group
+	schema, err := Parse(dest)
+	if err != nil {
+		tx.AddError(err)
+		return tx
 	}
 
-	// Optimization: Inline arguments to avoid driver binding issues
-	sql = Explain(sql, args...)
-
-	rows, err := db.DB.Query(sql)
+	rows, err := tx.DB.QueryContext(tx.context(), tx.buildQuery(tx.queryTable(schema)))
 	if err != nil {
 		tx.AddError(err)
 		return tx
@@ -77,16 +40,12 @@ func (db *DB) Find(dest interface{}) *DB {
 		elemType = destType
 	}
 
-	// Map columns to struct fields
-	// We need to find the struct field for each column
-	// Use schema to map column name to struct field
-	colToField := make(map[string]string)
-	for _, field := range schema.Fields {
-		colToField[field.Name] = field.StructFieldName
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		tx.AddError(err)
+		return tx
 	}
-
-	// Handle Tags as well (they are in schema.Fields)
-
+	scanner := tx.newRowScanner(schema, columns, columnTypes)
 	for rows.Next() {
 		// Create a new instance of the element
 		// elemType is usually *Struct or Struct
@@ -105,26 +64,7 @@ func (db *DB) Find(dest interface{}) *DB {
 			elem = scanElem
 		}
 
-		scanArgs := make([]interface{}, len(columns))
-		for i, colName := range columns {
-			fieldName, ok := colToField[colName]
-			if ok {
-				// We scan into scanElem (the struct value)
-				f := scanElem.FieldByName(fieldName)
-				if f.IsValid() {
-					scanArgs[i] = f.Addr().Interface()
-				} else {
-					var ignore interface{}
-					scanArgs[i] = &ignore
-				}
-			} else {
-				// Column not in struct, ignore
-				var ignore interface{}
-				scanArgs[i] = &ignore
-			}
-		}
-
-		if err := rows.Scan(scanArgs...); err != nil {
+		if err := scanner.scan(rows, scanElem); err != nil {
 			tx.AddError(err)
 			return tx
 		}
@@ -136,11 +76,296 @@ func (db *DB) Find(dest interface{}) *DB {
 			break // Only fetch one if not slice
 		}
 	}
+	if err := rows.Err(); err != nil {
+		tx.AddError(err)
+	}
 
 	return tx
 }
 
+// Rows runs the query of the statement and returns the rows to be scanned
+// one by one with ScanRows, the table is taken from Model or Table
+//
+//	rows, err := db.Model(&Sensor{}).Where("ts > ?", since).Rows()
+//	defer rows.Close()
+//	for rows.Next() {
+//		var s Sensor
+//		db.ScanRows(rows, &s)
+//	}
+func (db *DB) Rows() (*sql.Rows, error) {
+	tx := db.getInstance()
+	tableName := tx.Statement.Table
+	if tx.Statement.Model != nil {
+		schema, err := Parse(tx.Statement.Model)
+		if err != nil {
+			return nil, err
+		}
+		tableName = tx.queryTable(schema)
+	}
+	if tableName == "" {
+		return nil, fmt.Errorf("a Model or Table is required for Rows")
+	}
+	return tx.DB.QueryContext(tx.context(), tx.buildQuery(tableName))
+}
+
+// ScanRows scans the current row of rows into dest, a pointer to a model.
+// The model methods aren't called, only its cached fields are used.
+func (db *DB) ScanRows(rows *sql.Rows, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("ScanRows requires a non nil pointer, got %T", dest)
+	}
+	elem := indirectAlloc(rv.Elem())
+	scanner, err := db.rowScannerOf(rows, elem.Type())
+	if err != nil {
+		return err
+	}
+	return scanner.scan(rows, elem)
+}
+
+// rowScannerOf returns a rowScanner of rows into structs of modelType
+func (db *DB) rowScannerOf(rows *sql.Rows, modelType reflect.Type) (*rowScanner, error) {
+	schema, err := fieldsSchema(modelType)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	return db.newRowScanner(schema, columns, columnTypes), nil
+}
+
+// buildQuery returns the SELECT statement of tableName with the inlined
+// conditions and clauses of the statement
+func (db *DB) buildQuery(tableName string) string {
+	// Build Select
+	selectClause := "*"
+	if len(db.Statement.Selects) > 0 {
+		selectClause = strings.Join(db.Statement.Selects, ", ")
+	}
+
+	// Build Where
+	whereClause, args := db.Statement.BuildCondition()
+
+	sql := fmt.Sprintf("SELECT %s FROM %s%s", selectClause, Quote(tableName), whereClause)
+
+	// TDengine clause order: PARTITION BY, window, GROUP BY, ORDER BY, LIMIT
+	if db.Statement.Partition != "" {
+		sql += " PARTITION BY " + db.Statement.Partition
+	}
+
+	if db.Statement.Window != "" {
+		sql += " " + db.Statement.Window
+	}
+
+	if db.Statement.Group != "" {
+		sql += " GROUP BY " + db.Statement.Group
+	}
+
+	if db.Statement.Order != "" {
+		sql += " ORDER BY " + db.Statement.Order
+	}
+
+	if db.Statement.LimitVal > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", db.Statement.LimitVal)
+	}
+
+	if db.Statement.OffsetVal > 0 {
+		sql += fmt.Sprintf(" OFFSET %d", db.Statement.OffsetVal)
+	}
+
+	// Optimization: Inline arguments to avoid driver binding issues
+	return db.explain(sql, args...)
+}
+
+// rowScanner scans the rows of a query into structs, the columns are
+// mapped to the readable fields of the schema once per query
+type rowScanner struct {
+	fields     []*Field // Field of every column, nil for columns not in the struct
+	timestamps []bool   // TIMESTAMP columns, scanned as epochs into integer fields
+	precision  string
+}
+
+// newRowScanner maps columns to the fields of schema, columnTypes may be nil
+// when unknown
+func (db *DB) newRowScanner(schema *Schema, columns []string, columnTypes []*sql.ColumnType) *rowScanner {
+	fields := make([]*Field, len(columns))
+	timestamps := make([]bool, len(columns))
+	for i, colName := range columns {
+		if i < len(columnTypes) {
+			timestamps[i] = isTimestampColumn(columnTypes[i])
+		}
+		for _, f := range schema.Fields {
+			if f.Readable && strings.EqualFold(f.Name, colName) {
+				fields[i] = f
+				break
+			}
+		}
+	}
+	return &rowScanner{fields: fields, timestamps: timestamps, precision: db.precision()}
+}
+
+// scan scans the current row into the struct scanElem, unknown columns are ignored
+func (s *rowScanner) scan(rows *sql.Rows, scanElem reflect.Value) error {
+	scanArgs := make([]interface{}, len(s.fields))
+	for i, field := range s.fields {
+		if field == nil {
+			// Column not in struct, ignore
+			var ignore interface{}
+			scanArgs[i] = &ignore
+			continue
+		}
+
+		// We scan into scanElem (the struct value)
+		f := field.settableValueOf(scanElem)
+		if f.IsValid() && field.Serializer != nil {
+			scanArgs[i] = &fieldScanner{field: field, dst: f}
+		} else if f.IsValid() && (isEpochField(field) || (s.timestamps[i] && isIntegerType(f.Type()))) {
+			scanArgs[i] = &epochScanner{dst: f, precision: s.precision}
+		} else if f.IsValid() {
+			// sql.Scanner fields are handled by database/sql
+			scanArgs[i] = f.Addr().Interface()
+		} else {
+			var ignore interface{}
+			scanArgs[i] = &ignore
+		}
+	}
+	return rows.Scan(scanArgs...)
+}
+
 func (db *DB) First(dest interface{}) *DB {
 	tx := db.Limit(1).Find(dest)
 	return tx
 }
+
+// FindInBatches finds the rows matching the conditions into dest, a pointer
+// to a slice, in batches of batchSize rows and calls fc after each batch
+// with the batch number starting at 1. Batches are paged by the primary key
+// timestamp instead of OFFSET, in ascending order, Order, Limit and Offset
+// are ignored. Rows of different sub tables sharing the timestamp at the end
+// of a batch are moved to the next batch, which can exceed batchSize when
+// more of them than batchSize share it.
+func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, batch int) error) *DB {
+	tx := db.getInstance()
+	if batchSize <= 0 {
+		tx.AddError(fmt.Errorf("invalid batch size %d", batchSize))
+		return tx
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		tx.AddError(fmt.Errorf("FindInBatches requires a pointer to a slice, got %T", dest))
+		return tx
+	}
+	destValue = destValue.Elem()
+
+	schema, err := Parse(dest)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	primaryField := schema.PrimaryField()
+	if primaryField == nil {
+		tx.AddError(fmt.Errorf("%s has no primary key to page by", schema.Name))
+		return tx
+	}
+	primaryKey := Quote(primaryField.Name)
+
+	// keyOf returns the inlined primary key of the i-th row of dest
+	keyOf := func(i int) (string, error) {
+		val, err := primaryField.DBValueOf(reflect.Indirect(destValue.Index(i)))
+		if err != nil {
+			return "", err
+		}
+		return formatTagValue(val, tx.precision()), nil
+	}
+	find := func(condition string, limit int) error {
+		query := tx.Order(primaryKey).Limit(limit)
+		query.Statement.OffsetVal = 0
+		if condition != "" {
+			query = query.Where(condition)
+		}
+		destValue.Set(reflect.MakeSlice(destValue.Type(), 0, batchSize+1))
+		return query.Find(dest).Error
+	}
+
+	var cursor string // Primary key of the last row of the previous batch
+	for batch := 1; ; batch++ {
+		condition := ""
+		if cursor != "" {
+			condition = primaryKey + " > " + cursor
+		}
+		// One more row than needed tells whether the batch ends between two timestamps
+		if err := find(condition, batchSize+1); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+
+		last := destValue.Len() <= batchSize
+		if !last {
+			keys := make([]string, destValue.Len())
+			for i := range keys {
+				if keys[i], err = keyOf(i); err != nil {
+					tx.AddError(err)
+					return tx
+				}
+			}
+			boundary := keys[batchSize-1]
+			cut := batchSize
+			if keys[batchSize] == boundary {
+				// Move the rows sharing the boundary timestamp to the next batch
+				for cut > 0 && keys[cut-1] == boundary {
+					cut--
+				}
+			}
+			if cut > 0 {
+				destValue.Set(destValue.Slice(0, cut))
+				cursor = keys[cut-1]
+			} else {
+				// More rows share the timestamp than fit in a batch, fetch them all
+				if err := find(primaryKey+" = "+boundary, 0); err != nil {
+					tx.AddError(err)
+					return tx
+				}
+				cursor = boundary
+			}
+		}
+
+		count := destValue.Len()
+		if count == 0 {
+			break
+		}
+		tx.RowsAffected += int64(count)
+
+		batchTx := tx.getInstance()
+		batchTx.RowsAffected = int64(count)
+		if err := fc(batchTx, batch); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		if last {
+			break
+		}
+	}
+	return tx
+}
+
+// queryTable returns the table queried for schema: Table() if set, the
+// super table of models with tags, otherwise the model table
+func (db *DB) queryTable(schema *Schema) string {
+	if db.Statement.Table != "" {
+		return db.Statement.Table
+	}
+	if len(schema.Tags) > 0 {
+		// If it's a super table (has tags), query from super table by default
+		return schema.Name
+	}
+	if schema.TableName != "" {
+		return schema.TableName
+	}
+	return schema.Name
+}
@@ -0,0 +1,47 @@
+package teorm
+
+import "time"
+
+// WriteReport is the result of a Create, Save or Updates per target table,
+// in the order the tables were written
+type WriteReport struct {
+	Tables []*TableReport
+}
+
+// TableReport is the write result of one table
+type TableReport struct {
+	Table         string
+	RowsAttempted int
+	RowsWritten   int64
+	ColumnGroups  int // INSERT statements needed for the column signatures of the rows
+	Statements    int // Statements issued, including ALTER TABLE ... SET TAG
+	Duration      time.Duration
+	Error         error
+}
+
+// Failed returns the reports of the tables whose write failed
+func (r *WriteReport) Failed() []*TableReport {
+	if r == nil {
+		return nil
+	}
+	var failed []*TableReport
+	for _, table := range r.Tables {
+		if table.Error != nil {
+			failed = append(failed, table)
+		}
+	}
+	return failed
+}
+
+// Table returns the report of tableName, nil when it wasn't written
+func (r *WriteReport) Table(tableName string) *TableReport {
+	if r == nil {
+		return nil
+	}
+	for _, table := range r.Tables {
+		if table.Table == tableName {
+			return table
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package teorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	taosErrors "github.com/taosdata/driver-go/v3/errors"
+)
+
+// RetryPolicy retries write statements failing with transient errors, with
+// exponential backoff and jitter. Retrying an INSERT is safe as TDengine
+// overwrites rows with the same timestamp.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one,
+	// retries are disabled when it is 0 or 1
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, default 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, default 5s
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each attempt, default 2
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction of it, 0.2 spreads
+	// the waits over ±20%
+	Jitter float64
+	// IsTransient decides whether an error is retried, IsTransientError by default
+	IsTransient func(error) bool
+	// OnRetry is called before waiting for a retry, e.g. for logging
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy is a reasonable policy for Config.Retry
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// transientMessages are parts of TDengine and REST error messages of
+// failures that usually go away on their own
+var transientMessages = []string{
+	"not ready",
+	"is restoring",
+	"leader",
+	"unreachable",
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"server response: 502",
+	"server response: 503",
+	"server response: 504",
+}
+
+// IsTransientError reports whether err is a timeout or connection failure, a 5xx
+// gateway response of the REST server or a TDengine error of a vnode that
+// is temporarily unavailable
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	// A host that can't be resolved won't resolve on retry
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "read" || opErr.Op == "write") {
+		return true
+	}
+
+	msg := err.Error()
+	var taosErr *taosErrors.TaosError
+	if errors.As(err, &taosErr) {
+		msg = taosErr.ErrStr
+	}
+	msg = strings.ToLower(msg)
+	for _, part := range transientMessages {
+		if strings.Contains(msg, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before attempt (counting from 1 for the first retry)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	wait := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if wait > float64(max) {
+		wait = float64(max)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(wait)
+}
+
+// withRetry runs fn, retrying it on transient errors per Config.Retry
+func (db *DB) withRetry(fn func() error) error {
+	var policy RetryPolicy
+	if db.Config != nil {
+		policy = db.Config.Retry
+	}
+	isTransient := policy.IsTransient
+	if isTransient == nil {
+		isTransient = IsTransientError
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !isTransient(err) {
+			return err
+		}
+		wait := policy.backoff(attempt)
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-db.context().Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
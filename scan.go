@@ -0,0 +1,206 @@
+package teorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Scan runs the query of the statement, on the table of Model or Table, and
+// scans the result into dest independently of the model. dest may be a
+// pointer to a map[string]interface{}, a primitive (one column), a struct,
+// or a slice of them. Structs that aren't valid models are matched by the
+// `teorm:"column:name"` tag or the snake case field name.
+//
+//	var stats []struct {
+//		Location string
+//		AvgTemp  float64
+//	}
+//	db.Model(&Sensor{}).Select("location, avg(current_temp) AS avg_temp").Group("location").Scan(&stats)
+func (db *DB) Scan(dest interface{}) *DB {
+	tx := db.getInstance()
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		tx.AddError(fmt.Errorf("Scan requires a non nil pointer, got %T", dest))
+		return tx
+	}
+	destValue = destValue.Elem()
+
+	rows, err := tx.Rows()
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	isSlice := destValue.Kind() == reflect.Slice && destValue.Type().Elem().Kind() != reflect.Uint8
+	targetType := destValue.Type()
+	if isSlice {
+		targetType = targetType.Elem()
+		destValue.Set(reflect.MakeSlice(destValue.Type(), 0, 0))
+	}
+	scanRow, err := tx.scanPlan(targetType, columns, columnTypes)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	for rows.Next() {
+		target := destValue
+		if isSlice {
+			target = reflect.New(targetType).Elem()
+		}
+		if err := scanRow(rows, target); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		tx.RowsAffected++
+		if !isSlice {
+			break
+		}
+		destValue.Set(reflect.Append(destValue, target))
+	}
+	if err := rows.Err(); err != nil {
+		tx.AddError(err)
+	}
+	return tx
+}
+
+// Pluck queries a single column into dest, a pointer to a slice
+//
+//	var locations []string
+//	db.Model(&Sensor{}).Where("current_temp > ?", 30).Pluck("location", &locations)
+func (db *DB) Pluck(column string, dest interface{}) *DB {
+	tx := db.getInstance()
+	tx.Statement.Selects = []string{column}
+	return tx.Scan(dest)
+}
+
+// scanPlan returns the function scanning the current row into a settable
+// value of targetType, the columns are mapped once per query
+func (db *DB) scanPlan(targetType reflect.Type, columns []string, columnTypes []*sql.ColumnType) (func(*sql.Rows, reflect.Value) error, error) {
+	base := targetType
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+
+	switch {
+	case base == reflect.TypeOf(map[string]interface{}{}):
+		return func(rows *sql.Rows, target reflect.Value) error {
+			values := make([]interface{}, len(columns))
+			scanArgs := make([]interface{}, len(columns))
+			for i := range values {
+				scanArgs[i] = &values[i]
+			}
+			if err := rows.Scan(scanArgs...); err != nil {
+				return err
+			}
+			m := make(map[string]interface{}, len(columns))
+			for i, column := range columns {
+				m[column] = values[i]
+			}
+			indirectAlloc(target).Set(reflect.ValueOf(m))
+			return nil
+		}, nil
+
+	case base.Kind() == reflect.Struct && base != reflect.TypeOf(time.Time{}):
+		if schema, err := Parse(reflect.New(base).Interface()); err == nil {
+			scanner := db.newRowScanner(schema, columns, columnTypes)
+			return func(rows *sql.Rows, target reflect.Value) error {
+				return scanner.scan(rows, indirectAlloc(target))
+			}, nil
+		}
+		fields := resultFields(base, nil, make(map[string][]int))
+		return func(rows *sql.Rows, target reflect.Value) error {
+			elem := indirectAlloc(target)
+			scanArgs := make([]interface{}, len(columns))
+			for i, column := range columns {
+				index, ok := fields[strings.ToLower(column)]
+				if !ok {
+					var ignore interface{}
+					scanArgs[i] = &ignore
+					continue
+				}
+				scanArgs[i] = db.scanArg(elem.FieldByIndex(index), columnTypes[i])
+			}
+			return rows.Scan(scanArgs...)
+		}, nil
+
+	default:
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("scanning into %s requires a single column, got %d", targetType, len(columns))
+		}
+		return func(rows *sql.Rows, target reflect.Value) error {
+			return rows.Scan(db.scanArg(target, columnTypes[0]))
+		}, nil
+	}
+}
+
+// scanArg returns the Scan destination of dst, TIMESTAMP columns scanned
+// into integers hold the epoch in the database precision
+func (db *DB) scanArg(dst reflect.Value, columnType *sql.ColumnType) interface{} {
+	if isTimestampColumn(columnType) && isIntegerType(dst.Type()) {
+		return &epochScanner{dst: dst, precision: db.precision()}
+	}
+	return dst.Addr().Interface()
+}
+
+// isTimestampColumn reports whether columnType is a TIMESTAMP column
+func isTimestampColumn(columnType *sql.ColumnType) bool {
+	return columnType != nil && strings.EqualFold(columnType.DatabaseTypeName(), "TIMESTAMP")
+}
+
+// isIntegerType reports whether t, dereferenced, can hold an epoch timestamp
+func isIntegerType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// resultFields maps column names to the index paths of the exported fields
+// of an arbitrary struct, by `teorm:"column:name"` tag or snake case name
+func resultFields(structType reflect.Type, index []int, fields map[string][]int) map[string][]int {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldStruct := structType.Field(i)
+		if !fieldStruct.IsExported() {
+			continue
+		}
+		tag := fieldStruct.Tag.Get("teorm")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if fieldStruct.Anonymous && fieldStruct.Type.Kind() == reflect.Struct {
+			resultFields(fieldStruct.Type, fieldIndex, fields)
+			continue
+		}
+
+		name := ToSnakeCase(fieldStruct.Name)
+		if column := ParseTagSetting(tag)["COLUMN"]; column != "" {
+			name = column
+		}
+		name = strings.ToLower(name)
+		if _, ok := fields[name]; !ok {
+			fields[name] = fieldIndex
+		}
+	}
+	return fields
+}
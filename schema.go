@@ -1,10 +1,14 @@
 package teorm
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
-    "unicode"
+	"unicode"
 )
 
 type Tabler interface {
@@ -15,13 +19,30 @@ type Stabler interface {
 	StableName() string
 }
 
+// StableOptioner is implemented by models declaring super table options
+type StableOptioner interface {
+	StableOptions() StableOptions
+}
+
+// StableOptions are the table options of CREATE STABLE, zero values are
+// left to the TDengine defaults
+type StableOptions struct {
+	Comment   string
+	SMA       []string // Column names with SMA enabled
+	TTL       int      // Days
+	Watermark string   // e.g. "5s" or "5s,10m"
+	MaxDelay  string   // e.g. "5s" or "5s,10m"
+	Rollup    []string // Rollup functions, e.g. "avg"
+}
+
 type Schema struct {
 	Name      string
-    TableName string // Sub table name or normal table name
+	TableName string // Sub table name or normal table name
 	ModelType reflect.Type
 	Fields    []*Field
-	Tags      []*Field // Fields that are tags
-	Cols      []*Field // Fields that are normal columns
+	Tags      []*Field // Fields that are tags (writable)
+	Cols      []*Field // Fields that are normal columns (writable)
+	Options   StableOptions
 }
 
 type Field struct {
@@ -29,12 +50,20 @@ type Field struct {
 	StructFieldName string
 	Type            string
 	Tag             string // The raw tag string
-	IsTag           bool   // Is this a TDengine TAG?
+	TagSettings     map[string]string
+	IsTag           bool // Is this a TDengine TAG?
 	IsPrimaryKey    bool
+	Serializer      Serializer // Set by the `serializer` tag
+	Readable        bool       // Scanned by Find, false for write-only fields (`<-`)
+	Creatable       bool       // Written by Create and migrated, false for read-only fields (`->`)
+	FieldType       reflect.Type
+	Index           []int // Index path from the model struct, walks embedded structs
+
+	dataTypeErr error // Why a driver.Valuer field has no Type, reported by DDL only
 }
 
 // Parse parses a struct to a Schema
-func Parse(dest interface{}) *Schema {
+func Parse(dest interface{}) (*Schema, error) {
 	modelType := reflect.ValueOf(dest).Type()
 	// Handle pointer to slice or pointer to struct
 	for modelType.Kind() == reflect.Ptr {
@@ -61,12 +90,12 @@ func Parse(dest interface{}) *Schema {
 	if val.Kind() == reflect.Ptr && !val.IsNil() {
 		val = val.Elem()
 	}
-	
+
 	// If it's a slice, we can't determine dynamic tablename from the slice itself easily for all items
 	// But Parse is called per item in Create loop (Wait, no, Parse is called once in Create top level)
 	// In Create, we loop.
 	// But here Parse takes interface{}.
-	
+
 	// If dest is a valid struct instance (not zero value), we should use it to call TableName
 	if val.Kind() == reflect.Struct {
 		modelValue = val.Addr().Interface() // Get pointer to struct to satisfy interface receiver if pointer
@@ -81,11 +110,11 @@ func Parse(dest interface{}) *Schema {
 			schema.TableName = tabler.TableName()
 		}
 	}
-	
+
 	// Stabler is usually static (type level), so new instance is fine, but let's check instance too just in case
 	if stabler, ok := modelValue.(Stabler); ok {
-        schema.Name = stabler.StableName()
-    } else {
+		schema.Name = stabler.StableName()
+	} else {
 		// Try with new instance if the above failed (e.g. if modelValue was from dest but dest didn't impl Stabler on ptr?)
 		// Actually reflect.New(modelType) returns *Struct.
 		newValue := reflect.New(modelType).Interface()
@@ -94,78 +123,396 @@ func Parse(dest interface{}) *Schema {
 		}
 	}
 
-	for i := 0; i < modelType.NumField(); i++ {
-		fieldStruct := modelType.Field(i)
+	if optioner, ok := reflect.New(modelType).Interface().(StableOptioner); ok {
+		schema.Options = optioner.StableOptions()
+	}
+
+	if err := schema.loadFields(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// fieldsSchema returns a schema of modelType holding only the fields, without
+// calling the model methods, for scanning
+func fieldsSchema(modelType reflect.Type) (*Schema, error) {
+	schema := &Schema{Name: ToSnakeCase(modelType.Name()), ModelType: modelType}
+	if err := schema.loadFields(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// fieldsCache holds the parsed fields per model type, table names are still
+// resolved per Parse as TableName() usually depends on the row
+var fieldsCache sync.Map // reflect.Type -> *parsedFields
+
+type parsedFields struct {
+	Fields []*Field
+	Tags   []*Field
+	Cols   []*Field
+	Err    error
+}
+
+// loadFields sets the fields of the schema from the cache, parsing them on
+// the first use of the model type. Cached fields are shared and must not be
+// modified.
+func (schema *Schema) loadFields() error {
+	cached, ok := fieldsCache.Load(schema.ModelType)
+	if !ok {
+		parsed := &Schema{Name: schema.Name, ModelType: schema.ModelType}
+		err := parsed.parseFields(schema.ModelType, nil, "")
+		cached, _ = fieldsCache.LoadOrStore(schema.ModelType, &parsedFields{
+			Fields: parsed.Fields,
+			Tags:   parsed.Tags,
+			Cols:   parsed.Cols,
+			Err:    err,
+		})
+	}
+	fields := cached.(*parsedFields)
+	schema.Fields, schema.Tags, schema.Cols = fields.Fields, fields.Tags, fields.Cols
+	return fields.Err
+}
+
+// parseFields appends the fields of structType to the schema. Embedded
+// structs (anonymous fields or fields tagged with `embedded`) are flattened,
+// their columns optionally prefixed by `embeddedPrefix`.
+func (schema *Schema) parseFields(structType reflect.Type, index []int, prefix string) error {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldStruct := structType.Field(i)
+		if !fieldStruct.IsExported() && !fieldStruct.Anonymous {
+			continue
+		}
+
+		tag := fieldStruct.Tag.Get("teorm")
+		if tag == "-" {
+			continue
+		}
+		tagSetting := ParseTagSetting(tag)
+		if _, ok := tagSetting["-"]; ok {
+			continue
+		}
+
+		// Copy index path, appending to the parent slice could share its backing array
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		fieldType := fieldStruct.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// Flatten embedded structs
+		_, isEmbedded := tagSetting["EMBEDDED"]
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) && (fieldStruct.Anonymous || isEmbedded) {
+			embeddedPrefix := prefix
+			if val, ok := tagSetting["EMBEDDEDPREFIX"]; ok {
+				embeddedPrefix += val
+			}
+			if err := schema.parseFields(fieldType, fieldIndex, embeddedPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if !fieldStruct.IsExported() {
 			continue
 		}
 
 		field := &Field{
-			Name:            ToSnakeCase(fieldStruct.Name),
+			Name:            prefix + ToSnakeCase(fieldStruct.Name),
 			StructFieldName: fieldStruct.Name,
+			Tag:             tag,
+			TagSettings:     tagSetting,
+			Readable:        true,
+			Creatable:       true,
+			FieldType:       fieldStruct.Type,
+			Index:           fieldIndex,
+		}
+
+		if val, ok := tagSetting["COLUMN"]; ok {
+			field.Name = prefix + val
+		}
+
+		if _, ok := tagSetting["TAG"]; ok {
+			field.IsTag = true
+		}
+
+		if _, ok := tagSetting["PRIMARYKEY"]; ok {
+			field.IsPrimaryKey = true
+		}
+
+		// Permissions: `->` read-only, `<-` write-only
+		if _, ok := tagSetting["->"]; ok {
+			field.Creatable = false
+		}
+		if _, ok := tagSetting["<-"]; ok {
+			field.Readable = false
+		}
+
+		if val, ok := tagSetting["SERIALIZER"]; ok {
+			serializer, ok := GetSerializer(val)
+			if !ok {
+				return fmt.Errorf("unknown serializer %q for field %s", val, fieldStruct.Name)
+			}
+			field.Serializer = serializer
+		} else if field.IsTag && fieldType.Kind() == reflect.Map {
+			// JSON tags are written and scanned as JSON strings
+			field.Serializer = JSONSerializer{}
+		}
+
+		if val, ok := tagSetting["TYPE"]; ok {
+			field.Type = val
+		} else if field.Creatable {
+			dataType, err := DataTypeOf(field)
+			if err != nil && !isValuerType(field.FieldType) {
+				return err
+			}
+			// A Valuer is written and scanned through its own methods, it only
+			// needs a data type to be migrated
+			field.Type, field.dataTypeErr = dataType, err
 		}
 
-		// Parse tag
-		tagSetting := ParseTagSetting(fieldStruct.Tag.Get("teorm"))
-        
-        if val, ok := tagSetting["COLUMN"]; ok {
-            field.Name = val
-        }
-        
-        if _, ok := tagSetting["TAG"]; ok {
-            field.IsTag = true
-        }
-
-        if _, ok := tagSetting["PRIMARYKEY"]; ok {
-            field.IsPrimaryKey = true
-        }
-
-        if val, ok := tagSetting["TYPE"]; ok {
-            field.Type = val
-        } else {
-            field.Type = DataTypeOf(fieldStruct.Type)
-        }
-
-        if field.IsTag {
-            schema.Tags = append(schema.Tags, field)
-        } else {
-            schema.Cols = append(schema.Cols, field)
-        }
+		// Read-only fields are never written nor migrated, keep them out of Tags/Cols
+		if field.Creatable {
+			if err := ValidateName(field.Name, MaxColumnNameLength); err != nil {
+				return err
+			}
+			if field.IsTag {
+				schema.Tags = append(schema.Tags, field)
+			} else {
+				schema.Cols = append(schema.Cols, field)
+			}
+		}
 		schema.Fields = append(schema.Fields, field)
 	}
 
-	return schema
+	// TDengine requires a JSON tag to be the only tag of a super table
+	for _, field := range schema.Tags {
+		if field.Type == "JSON" && len(schema.Tags) > 1 {
+			return fmt.Errorf("JSON tag %s must be the only tag of %s", field.StructFieldName, schema.Name)
+		}
+	}
+	return nil
 }
 
-func DataTypeOf(t reflect.Type) string {
+// PrimaryField returns the timestamp primary key, the field tagged
+// `primaryKey` or the first column
+func (schema *Schema) PrimaryField() *Field {
+	for _, field := range schema.Cols {
+		if field.IsPrimaryKey {
+			return field
+		}
+	}
+	if len(schema.Cols) > 0 {
+		return schema.Cols[0]
+	}
+	return nil
+}
+
+// LookUpField finds a field by column name or Go field name
+func (schema *Schema) LookUpField(name string) *Field {
+	for _, field := range schema.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	for _, field := range schema.Fields {
+		if field.StructFieldName == name {
+			return field
+		}
+	}
+	// TDengine returns lowercase column names
+	for _, field := range schema.Fields {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// ReflectValueOf returns the value of the field inside the struct value rv,
+// following embedded struct pointers. The returned value is invalid when an
+// embedded pointer is nil, rv is never modified.
+func (field *Field) ReflectValueOf(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	for i, idx := range field.Index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv
+}
+
+// settableValueOf is like ReflectValueOf but allocates nil embedded pointers,
+// for scanning or assigning into the field. The returned value is invalid
+// when a nil embedded pointer can't be set.
+func (field *Field) settableValueOf(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	for i, idx := range field.Index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv
+}
+
+// DefaultSize is the length used for VARCHAR, NCHAR, VARBINARY and GEOMETRY
+// columns without a `size` tag
+const DefaultSize = 64
+
+// Size returns the `size` tag setting, or DefaultSize
+func (field *Field) Size() int {
+	if val, ok := field.TagSettings["SIZE"]; ok {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultSize
+}
+
+// DataTypeOf returns the TDengine data type for a field. It honors the
+// `size`, `nchar`, `geometry`, `precision` and `scale` tag settings and
+// returns an error for Go types that have no TDengine counterpart.
+func DataTypeOf(field *Field) (string, error) {
+	t := field.FieldType
 	// Handle pointer type
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
+	if val, ok := field.TagSettings["SIZE"]; ok {
+		if n, err := strconv.Atoi(val); err != nil || n <= 0 {
+			return "", fmt.Errorf("invalid size %q for field %s", val, field.StructFieldName)
+		}
+	}
+	size := field.Size()
+
+	if field.Serializer != nil {
+		if dataTyper, ok := field.Serializer.(SerializerDataTyper); ok {
+			return dataTyper.DataType(field), nil
+		}
+		return "", fmt.Errorf("serializer of field %s has no data type, use `type:` tag to specify the data type", field.StructFieldName)
+	}
+
+	// sql.NullString, sql.NullInt64, ... and sql.Null[T] map to the type of their value
+	if t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") && t.Kind() == reflect.Struct && t.NumField() == 2 {
+		valueField := *field
+		valueField.FieldType = t.Field(0).Type
+		return DataTypeOf(&valueField)
+	}
+
+	// DECIMAL(p,s), only supported by TDengine 3.3.6 and later
+	if val, ok := field.TagSettings["PRECISION"]; ok {
+		if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 && t.Kind() != reflect.String {
+			return "", fmt.Errorf("DECIMAL field %s must be a float or string, got %s", field.StructFieldName, t)
+		}
+		precision, err := strconv.Atoi(val)
+		if err != nil || precision < 1 || precision > 38 {
+			return "", fmt.Errorf("invalid decimal precision %q for field %s", val, field.StructFieldName)
+		}
+		scale := 0
+		if val, ok := field.TagSettings["SCALE"]; ok {
+			scale, err = strconv.Atoi(val)
+			if err != nil || scale < 0 || scale > precision {
+				return "", fmt.Errorf("invalid decimal scale %q for field %s", val, field.StructFieldName)
+			}
+		}
+		return fmt.Sprintf("DECIMAL(%d, %d)", precision, scale), nil
+	}
+
+	if _, ok := field.TagSettings["GEOMETRY"]; ok {
+		if t.Kind() != reflect.String {
+			return "", fmt.Errorf("GEOMETRY field %s must be a string (WKT), got %s", field.StructFieldName, t)
+		}
+		return fmt.Sprintf("GEOMETRY(%d)", size), nil
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
-		return "BOOL"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
-		return "INT"
-	case reflect.Int64:
-		return "BIGINT"
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-		return "INT UNSIGNED"
-	case reflect.Uint64:
-		return "BIGINT UNSIGNED"
+		return "BOOL", nil
+	case reflect.Int8:
+		return "TINYINT", nil
+	case reflect.Int16:
+		return "SMALLINT", nil
+	case reflect.Int32:
+		return "INT", nil
+	case reflect.Int, reflect.Int64:
+		// Epoch primary key in the database precision
+		if field.IsPrimaryKey {
+			return "TIMESTAMP", nil
+		}
+		return "BIGINT", nil
+	case reflect.Uint8:
+		return "TINYINT UNSIGNED", nil
+	case reflect.Uint16:
+		return "SMALLINT UNSIGNED", nil
+	case reflect.Uint32:
+		return "INT UNSIGNED", nil
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED", nil
 	case reflect.Float32:
-		return "FLOAT"
+		return "FLOAT", nil
 	case reflect.Float64:
-		return "DOUBLE"
+		return "DOUBLE", nil
 	case reflect.String:
-		return "BINARY(64)" // Default size
+		if _, ok := field.TagSettings["NCHAR"]; ok {
+			return fmt.Sprintf("NCHAR(%d)", size), nil
+		}
+		return fmt.Sprintf("VARCHAR(%d)", size), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("VARBINARY(%d)", size), nil
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			if !field.IsTag {
+				return "", fmt.Errorf("JSON field %s must be a tag", field.StructFieldName)
+			}
+			return "JSON", nil
+		}
 	case reflect.Struct:
 		if t == reflect.TypeOf(time.Time{}) {
-			return "TIMESTAMP"
+			return "TIMESTAMP", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s for field %s, use `type:` tag to specify the data type", t, field.StructFieldName)
+}
+
+// isValuerType reports whether t or *t implements driver.Valuer
+func isValuerType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	valuerType := reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType)
+}
+
+// checkDataTypes returns the error of the first field without a data type
+func (schema *Schema) checkDataTypes() error {
+	for _, field := range schema.Fields {
+		if field.dataTypeErr != nil {
+			return field.dataTypeErr
 		}
 	}
-	return "BINARY(64)"
+	return nil
 }
 
 func ParseTagSetting(str string) map[string]string {
@@ -186,15 +533,15 @@ func ParseTagSetting(str string) map[string]string {
 
 func ToSnakeCase(str string) string {
 	var matchFirstCap = unicode.IsUpper
-    
-    var sb strings.Builder
-    for i, r := range str {
-        if i > 0 && matchFirstCap(r) {
-             if !matchFirstCap(rune(str[i-1])) {
-                 sb.WriteRune('_')
-             }
-        }
-        sb.WriteRune(unicode.ToLower(r))
-    }
+
+	var sb strings.Builder
+	for i, r := range str {
+		if i > 0 && matchFirstCap(r) {
+			if !matchFirstCap(rune(str[i-1])) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
 	return sb.String()
 }
@@ -0,0 +1,228 @@
+package teorm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Serializer converts a field value to and from its database representation,
+// selected with the `serializer:name` tag
+type Serializer interface {
+	// Value returns the value written to the database for fieldValue
+	Value(field *Field, fieldValue interface{}) (interface{}, error)
+	// Scan decodes dbValue into dst, the settable field value
+	Scan(field *Field, dst reflect.Value, dbValue interface{}) error
+}
+
+// SerializerDataTyper is implemented by serializers that know the TDengine
+// data type they store, used by AutoMigrate when no `type:` tag is given
+type SerializerDataTyper interface {
+	DataType(field *Field) string
+}
+
+var serializers sync.Map
+
+func init() {
+	RegisterSerializer("json", JSONSerializer{})
+	RegisterSerializer("gob", GobSerializer{})
+	RegisterSerializer("unixtime", UnixSecondSerializer{})
+}
+
+// RegisterSerializer registers a serializer under name
+func RegisterSerializer(name string, serializer Serializer) {
+	serializers.Store(strings.ToLower(name), serializer)
+}
+
+// GetSerializer returns the serializer registered under name
+func GetSerializer(name string) (Serializer, bool) {
+	v, ok := serializers.Load(strings.ToLower(name))
+	if !ok {
+		return nil, false
+	}
+	return v.(Serializer), true
+}
+
+// DBValueOf returns the value of the field inside the struct value rv as it
+// is written to the database, applying the field serializer or driver.Valuer
+func (field *Field) DBValueOf(rv reflect.Value) (interface{}, error) {
+	fVal := field.ReflectValueOf(rv)
+	if !fVal.IsValid() || (fVal.Kind() == reflect.Ptr && fVal.IsNil()) {
+		return nil, nil
+	}
+
+	if field.Serializer != nil {
+		return field.Serializer.Value(field, fVal.Interface())
+	}
+
+	if valuer, ok := fVal.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	if fVal.CanAddr() {
+		if valuer, ok := fVal.Addr().Interface().(driver.Valuer); ok {
+			return valuer.Value()
+		}
+	}
+
+	if fVal.Kind() == reflect.Ptr {
+		return fVal.Elem().Interface(), nil
+	}
+	return fVal.Interface(), nil
+}
+
+// fieldScanner scans a column through the field serializer
+type fieldScanner struct {
+	field *Field
+	dst   reflect.Value
+}
+
+func (s *fieldScanner) Scan(src interface{}) error {
+	return s.field.Serializer.Scan(s.field, s.dst, src)
+}
+
+// setNil resets dst to its zero value, returns true when dbValue is nil
+func setNil(dst reflect.Value, dbValue interface{}) bool {
+	if dbValue == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return true
+	}
+	return false
+}
+
+// indirectAlloc allocates nil pointers of dst and returns the pointed value
+func indirectAlloc(dst reflect.Value) reflect.Value {
+	for dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	return dst
+}
+
+func bytesOf(dbValue interface{}) ([]byte, error) {
+	switch v := dbValue.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("failed to unmarshal value: %#v", dbValue)
+}
+
+// JSONSerializer stores the field as a JSON string
+type JSONSerializer struct{}
+
+func (JSONSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	b, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (JSONSerializer) Scan(field *Field, dst reflect.Value, dbValue interface{}) error {
+	if setNil(dst, dbValue) {
+		return nil
+	}
+	var b []byte
+	switch v := dbValue.(type) {
+	case []byte, string:
+		b, _ = bytesOf(v)
+	default:
+		// Drivers may return JSON tags already decoded
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, indirectAlloc(dst).Addr().Interface())
+}
+
+func (JSONSerializer) DataType(field *Field) string {
+	if field.IsTag && field.FieldType.Kind() == reflect.Map {
+		return "JSON"
+	}
+	return fmt.Sprintf("VARCHAR(%d)", field.Size())
+}
+
+// GobSerializer stores the field gob encoded
+type GobSerializer struct{}
+
+func (GobSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fieldValue); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobSerializer) Scan(field *Field, dst reflect.Value, dbValue interface{}) error {
+	if setNil(dst, dbValue) {
+		return nil
+	}
+	b, err := bytesOf(dbValue)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(indirectAlloc(dst).Addr().Interface())
+}
+
+func (GobSerializer) DataType(field *Field) string {
+	return fmt.Sprintf("VARBINARY(%d)", field.Size())
+}
+
+// UnixSecondSerializer stores an integer field holding unix seconds as TIMESTAMP
+type UnixSecondSerializer struct{}
+
+func (UnixSecondSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(fieldValue))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Unix(rv.Int(), 0), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Unix(int64(rv.Uint()), 0), nil
+	}
+	return nil, fmt.Errorf("invalid field type %#v for UnixSecondSerializer, only int, uint supported", fieldValue)
+}
+
+func (UnixSecondSerializer) Scan(field *Field, dst reflect.Value, dbValue interface{}) error {
+	if setNil(dst, dbValue) {
+		return nil
+	}
+	var seconds int64
+	switch v := dbValue.(type) {
+	case time.Time:
+		seconds = v.Unix()
+	case int64:
+		seconds = v
+	default:
+		return fmt.Errorf("failed to scan %#v with UnixSecondSerializer", dbValue)
+	}
+	dst = indirectAlloc(dst)
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(seconds)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dst.SetUint(uint64(seconds))
+	default:
+		return fmt.Errorf("invalid field type %s for UnixSecondSerializer, only int, uint supported", dst.Type())
+	}
+	return nil
+}
+
+func (UnixSecondSerializer) DataType(field *Field) string {
+	return "TIMESTAMP"
+}
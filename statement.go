@@ -1,17 +1,26 @@
 package teorm
 
-import "strings"
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 type Statement struct {
-	Table       string
-	Model       interface{}
-	Selects     []string
-	Conditions  []string
-	Args        []interface{}
-	LimitVal    int
-	OffsetVal   int
-	Order       string
-	Group       string
+	Context    context.Context
+	Table      string
+	Model      interface{}
+	Selects    []string
+	Omits      []string
+	Conditions []string
+	Args       []interface{}
+	LimitVal   int
+	OffsetVal  int
+	Order      string
+	Group      string
+	Partition  string // PARTITION BY columns
+	Window     string // Window clause, e.g. INTERVAL(1m)
 }
 
 func (s *Statement) Clone() *Statement {
@@ -19,13 +28,16 @@ func (s *Statement) Clone() *Statement {
 	// Copy slices to avoid sharing backing arrays
 	newStmt.Selects = make([]string, len(s.Selects))
 	copy(newStmt.Selects, s.Selects)
-	
+
+	newStmt.Omits = make([]string, len(s.Omits))
+	copy(newStmt.Omits, s.Omits)
+
 	newStmt.Conditions = make([]string, len(s.Conditions))
 	copy(newStmt.Conditions, s.Conditions)
-	
+
 	newStmt.Args = make([]interface{}, len(s.Args))
 	copy(newStmt.Args, s.Args)
-	
+
 	return &newStmt
 }
 
@@ -35,3 +47,59 @@ func (s *Statement) BuildCondition() (string, []interface{}) {
 	}
 	return " WHERE " + strings.Join(s.Conditions, " AND "), s.Args
 }
+
+// splitColumns splits comma separated column lists
+func splitColumns(values []string) []string {
+	var columns []string
+	for _, value := range values {
+		for _, column := range strings.Split(value, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+// writeFilter restricts filter to the columns chosen by Select and Omit,
+// the primary key is always written
+func (s *Statement) writeFilter(schema *Schema, filter columnFilter) (columnFilter, error) {
+	if len(s.Selects) == 0 && len(s.Omits) == 0 {
+		return filter, nil
+	}
+
+	lookUp := func(names []string) (map[*Field]bool, error) {
+		fields := make(map[*Field]bool)
+		for _, name := range splitColumns(names) {
+			if name == "*" {
+				return nil, nil
+			}
+			field := schema.LookUpField(name)
+			if field == nil {
+				return nil, fmt.Errorf("unknown column %s of %s", name, schema.Name)
+			}
+			fields[field] = true
+		}
+		return fields, nil
+	}
+
+	selected, err := lookUp(s.Selects)
+	if err != nil {
+		return nil, err
+	}
+	omitted, err := lookUp(s.Omits)
+	if err != nil {
+		return nil, err
+	}
+	primaryField := schema.PrimaryField()
+
+	return func(elem reflect.Value, field *Field) bool {
+		if field == primaryField {
+			return true
+		}
+		if omitted[field] || (selected != nil && !selected[field]) {
+			return false
+		}
+		return filter(elem, field)
+	}, nil
+}
@@ -0,0 +1,195 @@
+package teorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CreateSubTablesBatchSize is the number of sub tables created per statement
+var CreateSubTablesBatchSize = 100
+
+// CreateSubTables creates the sub tables of models (structs, pointers or
+// slices) from their TableName() and tag fields, using the multi-table
+// CREATE TABLE syntax
+func (db *DB) CreateSubTables(models ...interface{}) *DB {
+	tx := db.getInstance()
+
+	var clauses []string
+	seen := make(map[string]bool)
+	for _, elem := range flattenValues(models) {
+		schema, err := Parse(elem.Addr().Interface())
+		if err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		if len(schema.Tags) == 0 {
+			tx.AddError(fmt.Errorf("%s has no tags, sub tables require a super table", schema.Name))
+			return tx
+		}
+		tableName := schema.TableName
+		if tableName == "" {
+			tx.AddError(fmt.Errorf("table name is required for sub tables of %s, implement Tabler interface", schema.Name))
+			return tx
+		}
+		if err := ValidateName(tableName, MaxTableNameLength); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		if seen[tableName] {
+			continue
+		}
+		seen[tableName] = true
+
+		tagValues, err := tx.formatTagValues(elem, schema)
+		if err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		clauses = append(clauses, fmt.Sprintf("IF NOT EXISTS %s USING %s TAGS (%s)", Quote(tableName), Quote(schema.Name), strings.Join(tagValues, ", ")))
+	}
+
+	for start := 0; start < len(clauses); start += CreateSubTablesBatchSize {
+		end := start + CreateSubTablesBatchSize
+		if end > len(clauses) {
+			end = len(clauses)
+		}
+		if err := tx.Exec("CREATE TABLE " + strings.Join(clauses[start:end], " ")).Error; err != nil {
+			tx.AddError(err)
+			return tx
+		}
+	}
+	return tx
+}
+
+// UpdateTags sets the tags of the sub table of model to the model tag
+// values, only changed tags are altered
+func (db *DB) UpdateTags(model interface{}) *DB {
+	tx := db.getInstance()
+
+	schema, err := Parse(model)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	tableName := tx.Statement.Table
+	if tableName == "" {
+		tableName = schema.TableName
+	}
+	if tableName == "" || len(schema.Tags) == 0 {
+		tx.AddError(fmt.Errorf("a sub table name and tags are required to update tags of %s", schema.Name))
+		return tx
+	}
+
+	elem := reflect.Indirect(reflect.ValueOf(model))
+	wantValues, err := tx.formatTagValues(elem, schema)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	liveValues, err := tx.queryTagValues(schema, tableName)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	for i, field := range schema.Tags {
+		if liveValues[i] == wantValues[i] {
+			continue
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s SET TAG %s = %s", Quote(tableName), Quote(field.Name), wantValues[i])).Error; err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		tx.RowsAffected++
+	}
+	return tx
+}
+
+// formatTagValues returns the inlined tag values of elem in schema.Tags order
+func (db *DB) formatTagValues(elem reflect.Value, schema *Schema) ([]string, error) {
+	var values []string
+	for _, field := range schema.Tags {
+		val, err := field.DBValueOf(elem)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, formatTagValue(val, db.precision()))
+	}
+	return values, nil
+}
+
+// queryTagValues returns the inlined tag values of a live sub table in schema.Tags order
+func (db *DB) queryTagValues(schema *Schema, tableName string) ([]string, error) {
+	var tagNames []string
+	for _, field := range schema.Tags {
+		tagNames = append(tagNames, Quote(field.Name))
+	}
+	sqlStr := fmt.Sprintf("SELECT TAGS %s FROM %s WHERE tbname = %s", strings.Join(tagNames, ", "), Quote(schema.Name), formatTagValue(strings.ToLower(tableName), db.precision()))
+
+	rows, err := db.DB.QueryContext(db.context(), sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("sub table %s of %s not found", tableName, schema.Name)
+	}
+	values := make([]interface{}, len(tagNames))
+	scanArgs := make([]interface{}, len(tagNames))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(values))
+	for i, v := range values {
+		// JSON tags come back as raw JSON, compare them as strings
+		if b, ok := v.([]byte); ok && schema.Tags[i].Serializer != nil {
+			v = string(b)
+		}
+		result[i] = formatTagValue(v, db.precision())
+	}
+	return result, nil
+}
+
+// flattenValues returns the addressable struct values of values, which may
+// be structs, pointers or slices of them
+func flattenValues(values []interface{}) []reflect.Value {
+	var result []reflect.Value
+	for _, value := range values {
+		rv := reflect.ValueOf(value)
+		for rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if rv.Kind() == reflect.Slice {
+			for i := 0; i < rv.Len(); i++ {
+				result = append(result, addressable(rv.Index(i)))
+			}
+			continue
+		}
+		result = append(result, addressable(rv))
+	}
+	return result
+}
+
+// addressable dereferences pointers and copies unaddressable structs so
+// Addr() can be used to call pointer receiver methods
+func addressable(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if !rv.CanAddr() {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv = ptr.Elem()
+	}
+	return rv
+}
@@ -1,22 +1,77 @@
 package teorm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	_ "github.com/taosdata/driver-go/v3/taosRestful"
 )
 
+// Config is the teorm configuration, shared by all DB instances derived from Open
+type Config struct {
+	// Precision is the timestamp precision of the database ("ms", "us" or "ns"),
+	// discovered from the database when empty
+	Precision string
+
+	// AllowDestructiveMigration lets AutoMigrate drop columns and tags that
+	// were removed from the model or whose type changed
+	AllowDestructiveMigration bool
+
+	// AllowGlobalDelete allows Delete and DropSubTables without conditions
+	AllowGlobalDelete bool
+
+	// TagPolicy decides what Create does when rows of the same sub table
+	// have different tag values, TagPolicyError by default
+	TagPolicy TagPolicy
+
+	// Retry is the retry policy of the statements issued by Create and
+	// Save, statements are not retried by default
+	Retry RetryPolicy
+
+	// DeadLetter receives the rows of INSERT statements that failed after
+	// all retries, they are only reported in Error when nil
+	DeadLetter DeadLetter
+}
+
+// TagPolicy is the handling of divergent tag values within a sub table batch
+type TagPolicy int
+
+const (
+	// TagPolicyError rejects the batch of the sub table
+	TagPolicyError TagPolicy = iota
+	// TagPolicyWarn logs a warning and keeps the tags of the first row
+	TagPolicyWarn
+	// TagPolicySplit inserts rows in groups of equal tags, issuing
+	// ALTER TABLE ... SET TAG between groups so the last row's tags win
+	TagPolicySplit
+)
+
 // DB is the main struct for teorm
 type DB struct {
 	DB           *sql.DB
+	Config       *Config
 	Statement    *Statement
 	Error        error
 	RowsAffected int64
+	// Report is the per table result of the last Create, Save or Updates
+	Report *WriteReport
+
+	dsn         string
+	tableReport *TableReport // Report entry of the table being written
 }
 
-// Open initializes a new DB connection
-func Open(dsn string) (*DB, error) {
+// Open initializes a new DB connection. The config is copied, so one Config
+// can be passed to several Open calls, each discovering its own precision.
+func Open(dsn string, configs ...*Config) (*DB, error) {
+	config := &Config{}
+	if len(configs) > 0 && configs[0] != nil {
+		*config = *configs[0]
+	}
+	return open(dsn, config)
+}
+
+func open(dsn string, config *Config) (*DB, error) {
 	// Using taosRestful driver as port 6041 implies REST interface
 	db, err := sql.Open("taosRestful", dsn)
 	if err != nil {
@@ -24,22 +79,54 @@ func Open(dsn string) (*DB, error) {
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if config.Precision == "" {
+		precision, err := queryPrecision(db)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to query database precision: %w", err)
+		}
+		config.Precision = precision
+	}
+	if !validPrecision(config.Precision) {
+		db.Close()
+		return nil, fmt.Errorf("invalid precision %q, must be one of ms, us, ns", config.Precision)
+	}
+
 	return &DB{
 		DB:        db,
+		Config:    config,
 		Statement: &Statement{},
+		dsn:       dsn,
 	}, nil
 }
 
+// Close closes the connection pool of db, shared with the DB instances
+// derived from it
+func (db *DB) Close() error {
+	return db.DB.Close()
+}
+
 // getInstance returns a new DB instance for chaining
 func (db *DB) getInstance() *DB {
 	return &DB{
 		DB:        db.DB,
+		Config:    db.Config,
 		Statement: db.Statement.Clone(),
 		Error:     db.Error,
+		dsn:       db.dsn,
+	}
+}
+
+// context returns the context set by WithContext, or context.Background()
+func (db *DB) context() context.Context {
+	if db.Statement.Context != nil {
+		return db.Statement.Context
 	}
+	return context.Background()
 }
 
 func (db *DB) AddError(err error) error {
@@ -53,7 +140,7 @@ func (db *DB) AddError(err error) error {
 
 func (db *DB) Exec(sql string, args ...interface{}) *DB {
 	tx := db.getInstance()
-	res, err := tx.DB.Exec(sql, args...)
+	res, err := tx.DB.ExecContext(tx.context(), sql, args...)
 	if err != nil {
 		tx.AddError(err)
 		return tx
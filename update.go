@@ -0,0 +1,125 @@
+package teorm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Updates writes the given columns of the row of Model at its primary key
+// timestamp, TDengine merges them into the existing row and keeps the other
+// columns. values is a map[string]interface{} keyed by column or field name,
+// or a struct of the model type whose non-zero fields are written. The
+// values are assigned to the model as well.
+//
+//	db.Model(&sensor).Updates(map[string]interface{}{"current_temp": 26.1})
+func (db *DB) Updates(values interface{}) *DB {
+	tx := db.getInstance()
+
+	model := tx.Statement.Model
+	modelValue := reflect.ValueOf(model)
+	if model == nil || modelValue.Kind() != reflect.Ptr || modelValue.Elem().Kind() != reflect.Struct {
+		tx.AddError(fmt.Errorf("Updates requires a pointer to a struct, use db.Model(&value)"))
+		return tx
+	}
+	elem := modelValue.Elem()
+
+	schema, err := Parse(model)
+	if err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	primaryField := schema.PrimaryField()
+	if primaryField == nil {
+		tx.AddError(fmt.Errorf("%s has no primary key", schema.Name))
+		return tx
+	}
+
+	selected := map[*Field]bool{primaryField: true}
+
+	switch v := values.(type) {
+	case map[string]interface{}:
+		for name, val := range v {
+			field := schema.LookUpField(name)
+			if field == nil || !field.Creatable {
+				tx.AddError(fmt.Errorf("unknown column %s of %s", name, schema.Name))
+				return tx
+			}
+			if field.IsTag {
+				tx.AddError(fmt.Errorf("tag %s can not be updated with Updates, use UpdateTags", name))
+				return tx
+			}
+			if err := setFieldValue(field.settableValueOf(elem), val); err != nil {
+				tx.AddError(fmt.Errorf("failed to set %s: %w", name, err))
+				return tx
+			}
+			selected[field] = true
+		}
+	default:
+		rv := reflect.Indirect(reflect.ValueOf(values))
+		if !rv.IsValid() || rv.Type() != elem.Type() {
+			tx.AddError(fmt.Errorf("Updates expects a map[string]interface{} or %s, got %T", elem.Type(), values))
+			return tx
+		}
+		for _, field := range schema.Cols {
+			fVal := field.ReflectValueOf(rv)
+			if !fVal.IsValid() || fVal.IsZero() {
+				continue
+			}
+			field.settableValueOf(elem).Set(fVal)
+			selected[field] = true
+		}
+	}
+
+	if pkVal := primaryField.ReflectValueOf(elem); !pkVal.IsValid() || pkVal.IsZero() {
+		tx.AddError(fmt.Errorf("primary key %s is required to update a row", primaryField.Name))
+		return tx
+	}
+
+	return tx.write(model, func(elem reflect.Value, field *Field) bool {
+		return selected[field]
+	})
+}
+
+// setFieldValue assigns val to dst, allocating and converting as needed
+func setFieldValue(dst reflect.Value, val interface{}) error {
+	if !dst.IsValid() {
+		return fmt.Errorf("field is not settable")
+	}
+	if val == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	dst = indirectAlloc(dst)
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+	// Converting an integer to a string yields a rune, not its digits
+	if rv.Type().ConvertibleTo(dst.Type()) && !(dst.Kind() == reflect.String && isNumberKind(rv.Kind())) {
+		dst.Set(rv.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("can not assign %T to %s", val, dst.Type())
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}